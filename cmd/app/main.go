@@ -8,11 +8,16 @@ import (
 
 	"github.com/PxyUp/ton_games_example/cmd/app/router"
 	"github.com/PxyUp/ton_games_example/cmd/app/server"
+	"github.com/PxyUp/ton_games_example/pkg/activity"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
 	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/PxyUp/ton_games_example/pkg/database"
 	logger2 "github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/matchmaking"
 	"github.com/PxyUp/ton_games_example/pkg/runtime"
+	"github.com/PxyUp/ton_games_example/pkg/schedule"
 	"github.com/PxyUp/ton_games_example/pkg/telegram"
+	"github.com/PxyUp/ton_games_example/pkg/tournament"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -21,6 +26,11 @@ import (
 )
 
 func main() {
+	errConfig := config.Load()
+	if errConfig != nil {
+		log.Fatalf("config parsing failed: %v\n", errConfig)
+	}
+
 	mainCtx := context.Background()
 	logger := logger2.NewLogger(config.Config.LoggerLevel)
 
@@ -41,12 +51,30 @@ func main() {
 		}()
 	}
 
-	gameEngine, err := database.New(mainCtx, bunDb, logger.With("component", "database"), config.Config.SettingsID)
+	bus := activity.New()
+
+	gameEngine, err := database.New(mainCtx, bunDb, logger.With("component", "database"), config.Config.SettingsID, bus)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	rt := runtime.New(mainCtx, gameEngine, logger.With("component", "runtime"))
+	rt := runtime.New(mainCtx, gameEngine, logger.With("component", "runtime"), clock.New())
+
+	tournaments := tournament.New(mainCtx, gameEngine, rt, logger.With("component", "tournament"))
+
+	matchmaker := matchmaking.New(mainCtx, gameEngine, rt, logger.With("component", "matchmaking"))
+
+	if config.Config.HouseSchedule != "" {
+		entries, errSchedule := schedule.Parse(config.Config.HouseSchedule)
+		if errSchedule != nil {
+			log.Fatal(errSchedule)
+		}
+
+		errSchedule = schedule.Run(mainCtx, gameEngine, rt, entries, logger.With("component", "schedule"))
+		if errSchedule != nil {
+			log.Fatal(errSchedule)
+		}
+	}
 
 	bot := telegram.New(mainCtx, logger.With("component", "bot"))
 
@@ -55,7 +83,7 @@ func main() {
 		log.Fatal(srv.Listen(mainCtx, acc))
 	}()
 
-	log.Fatal(router.New(gameEngine, rt, srv, address, bot.WebHookHandler, logger.With("component", "router")).Start(fmt.Sprintf(":%v", config.Config.Port)))
+	log.Fatal(router.New(gameEngine, rt, bus, tournaments, matchmaker, srv, address, bot.WebHookHandler, logger.With("component", "router")).Start(fmt.Sprintf(":%v", config.Config.Port)))
 }
 
 func setupMonitoring() {
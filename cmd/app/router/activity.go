@@ -0,0 +1,129 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/pkg/activity"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/http_server"
+	"github.com/PxyUp/ton_games_example/pkg/logger"
+	echo "github.com/labstack/echo/v4"
+	"github.com/xssnick/tonutils-go/tlb"
+)
+
+func activityJSON(event *activity.Event, balance database.BalanceRecord) map[string]interface{} {
+	amount := tlb.FromNanoTONU(uint64(event.Amount)).String()
+	if event.Amount < 0 {
+		amount = "-" + tlb.FromNanoTONU(uint64(-event.Amount)).String()
+	}
+
+	resp := map[string]interface{}{
+		"kind":      event.Kind,
+		"timestamp": event.Timestamp,
+		"amount":    amount,
+		"balance":   balance.JSON(),
+	}
+
+	if event.GameID != "" {
+		resp["game_id"] = event.GameID
+	}
+
+	if event.TicketID != "" {
+		resp["ticket_id"] = event.TicketID
+	}
+
+	if event.Tx != nil {
+		resp["tx"] = event.Tx
+	}
+
+	return resp
+}
+
+// accountActivity push balance changes of the user as server-sent events, every event carry balance after the change.
+func accountActivity(h http_server.Handlers, store database.DB, bus activity.Bus, log logger.Logger) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, err := h.GetUserFromCtx(c)
+		if err != nil {
+			log.Errorw("cant get user from ctx", "error", err.Error())
+			return errorResponse(c, http.StatusUnauthorized, nil)
+		}
+
+		sub := bus.Subscribe(user.GetId(), user.GetAddress(), config.ACTIVITY_BUFFER)
+		defer sub.Close()
+
+		res := c.Response()
+		// write timeout of http server is too short for stream, deadline moved before every write
+		rc := http.NewResponseController(res)
+
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set("Cache-Control", "no-cache")
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+
+		errWrite := writeEvent(rc, res, "", nil)
+		if errWrite != nil {
+			return nil
+		}
+
+		keepAlive := time.NewTicker(config.SSE_KEEP_ALIVE)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case <-keepAlive.C:
+				errWrite = writeEvent(rc, res, "", nil)
+			case event, ok := <-sub.Events():
+				if !ok {
+					return nil
+				}
+
+				balance, errBalance := store.GetBalanceByAddress(c.Request().Context(), user.GetAddress())
+				if errBalance != nil {
+					log.Errorw("cant get balance of the account", "error", errBalance.Error())
+					return nil
+				}
+
+				errWrite = writeEvent(rc, res, string(event.Kind), activityJSON(event, balance))
+			}
+
+			if errWrite != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// writeEvent write one server-sent event and flush it, event without name is keep alive comment.
+func writeEvent(rc *http.ResponseController, res *echo.Response, name string, data interface{}) error {
+	errDeadline := rc.SetWriteDeadline(time.Now().Add(config.SSE_WRITE_TIMEOUT))
+	if errDeadline != nil {
+		return errDeadline
+	}
+
+	if name == "" {
+		_, err := fmt.Fprint(res, ": keep-alive\n\n")
+		if err != nil {
+			return err
+		}
+
+		return rc.Flush()
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", name, payload)
+	if err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
@@ -1,8 +1,16 @@
 package router
 
 import (
-	"github.com/labstack/echo/v4"
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
+
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/invite"
+	"github.com/PxyUp/ton_games_example/pkg/payout"
+	"github.com/labstack/echo/v4"
 )
 
 type Header struct {
@@ -22,3 +30,50 @@ func defaultHeaders(headers []Header, filter func(r *http.Request) bool) echo.Mi
 		}
 	}
 }
+
+// readPayload return raw request body, empty body treated as empty json object.
+func readPayload(c echo.Context) (json.RawMessage, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+
+	return body, nil
+}
+
+// inviteJSON return invite code and signed invite link of private game.
+func inviteJSON(rec database.GameRecord) map[string]interface{} {
+	resp := map[string]interface{}{
+		"code": rec.GetInviteCode(),
+	}
+
+	token, err := invite.Token(config.Config.PayloadSignatureKey, rec.GetId())
+	if err == nil {
+		resp["token"] = token
+		resp["link"] = invite.Link(token)
+	}
+
+	return resp
+}
+
+// settlementOption validate payout scheme of the game.
+func settlementOption(cfg *GameSettlementConfig) (database.GameOption, error) {
+	scheme, err := payout.New(cfg.Payout, cfg.PayoutWeights)
+	if err != nil {
+		return nil, err
+	}
+
+	var weights []uint64
+	if scheme.Name() == payout.TopN {
+		weights, err = payout.ToBasisPoints(cfg.PayoutWeights)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return database.WithPayout(scheme, weights), nil
+}
@@ -1,22 +1,33 @@
 package router
 
 import (
-	"fmt"
+	"encoding/json"
 	"io"
 	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/PxyUp/ton_games_example/games"
-	morelessGame "github.com/PxyUp/ton_games_example/games/more_less/game"
-	rockPaperGame "github.com/PxyUp/ton_games_example/games/rock_paper_scissors"
+	_ "github.com/PxyUp/ton_games_example/games/crash"
+	_ "github.com/PxyUp/ton_games_example/games/dice_duel"
+	_ "github.com/PxyUp/ton_games_example/games/jackpot"
+	_ "github.com/PxyUp/ton_games_example/games/lowest_unique"
+	_ "github.com/PxyUp/ton_games_example/games/more_less/game"
+	_ "github.com/PxyUp/ton_games_example/games/rock_paper_scissors"
+	_ "github.com/PxyUp/ton_games_example/games/tic_tac_toe"
+	"github.com/PxyUp/ton_games_example/pkg/activity"
 	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/PxyUp/ton_games_example/pkg/database"
 	"github.com/PxyUp/ton_games_example/pkg/http_server"
+	"github.com/PxyUp/ton_games_example/pkg/invite"
 	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/matchmaking"
+	"github.com/PxyUp/ton_games_example/pkg/replay"
 	"github.com/PxyUp/ton_games_example/pkg/runtime"
 	"github.com/PxyUp/ton_games_example/pkg/server"
 	"github.com/PxyUp/ton_games_example/pkg/ton"
+	"github.com/PxyUp/ton_games_example/pkg/tournament"
 	"github.com/golang-jwt/jwt"
 	echo "github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -28,6 +39,20 @@ type WithdrawalConfig struct {
 	Amount float64 `json:"amount"`
 }
 
+// GameAccessConfig common part of create/join payload of any game type.
+type GameAccessConfig struct {
+	Private bool   `json:"private"`
+	Invite  string `json:"invite"`
+}
+
+// GameSettlementConfig payout part of create payload of any game type, split by default.
+// Rake is set by operator for game type, not by creator.
+type GameSettlementConfig struct {
+	Payout string `json:"payout"`
+	// PayoutWeights percents of the bank by place for top_n scheme
+	PayoutWeights []float64 `json:"payout_weights"`
+}
+
 func errorResponse(c echo.Context, statusCode int, e error) error {
 	if e == nil {
 		e = io.EOF
@@ -37,7 +62,7 @@ func errorResponse(c echo.Context, statusCode int, e error) error {
 	})
 }
 
-func New(store database.DB, runtime runtime.Runtime, server server.Server, address string, botHandler echo.HandlerFunc, logger logger.Logger) *echo.Echo {
+func New(store database.DB, runtime runtime.Runtime, bus activity.Bus, tournaments tournament.Manager, matchmaker matchmaking.Matchmaker, server server.Server, address string, botHandler echo.HandlerFunc, logger logger.Logger) *echo.Echo {
 	e := echo.New()
 
 	{
@@ -117,6 +142,21 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 			})
 		}
 	}
+	{
+		// browsers cant set headers on websocket handshake and EventSource, so short-lived ticket passed in query instead of token
+		queryAuth := middleware.JWTWithConfig(middleware.JWTConfig{
+			Claims:      &http_server.JwtCustomClaims{},
+			SigningKey:  ticketKey(),
+			TokenLookup: "query:ticket",
+		})
+
+		wsGroup := e.Group("/api/ws", queryAuth)
+
+		wsGroup.GET("/games/:gameId", gameStream(h, runtime, logger))
+		wsGroup.GET("/lobby/:gameType", lobbyStream(h, runtime, logger))
+
+		e.GET("/api/activity", accountActivity(h, store, bus, logger), queryAuth)
+	}
 	{
 		apiGroup := e.Group("/api", middleware.JWTWithConfig(middleware.JWTConfig{
 			Claims:     &http_server.JwtCustomClaims{},
@@ -124,6 +164,24 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 		}))
 
 		{
+			apiGroup.POST("/ticket", func(c echo.Context) error {
+				user, err := h.GetUserFromCtx(c)
+				if err != nil {
+					logger.Errorw("cant get user from ctx", "error", err.Error())
+					return errorResponse(c, http.StatusUnauthorized, nil)
+				}
+
+				ticket, err := streamTicket(user.GetId())
+				if err != nil {
+					return errorResponse(c, http.StatusInternalServerError, err)
+				}
+
+				return c.JSON(http.StatusOK, echo.Map{
+					"ticket":     ticket,
+					"expires_in": int(config.WS_TICKET_TTL.Seconds()),
+				})
+			})
+
 			apiGroup.GET("/getAccountInfo", func(c echo.Context) error {
 				user, err := h.GetUserFromCtx(c)
 				if err != nil {
@@ -203,37 +261,51 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 				gameGroup := apiGroup.Group("/games")
 
 				gameGroup.GET("/lasts", func(c echo.Context) error {
-					switch c.Param("gameType") {
-					case fmt.Sprintf("%d", games.MoreLess):
-						ggRecords, errDb := store.GetActiveGames(c.Request().Context(), games.MoreLess)
-						if errDb != nil {
-							return errorResponse(c, http.StatusBadRequest, errDb)
-						}
+					gameType, errType := games.ParseGameType(c.QueryParam("gameType"))
+					if errType != nil {
+						return errorResponse(c, http.StatusBadRequest, errType)
+					}
 
-						resp := make([]map[string]interface{}, len(ggRecords))
+					ggRecords, errDb := store.GetActiveGames(c.Request().Context(), gameType)
+					if errDb != nil {
+						return errorResponse(c, http.StatusBadRequest, errDb)
+					}
 
-						for index, i := range ggRecords {
-							resp[index] = i.JSON()
-						}
+					resp := make([]map[string]interface{}, len(ggRecords))
 
-						return c.JSON(http.StatusOK, echo.Map{
-							"games": resp,
-						})
-					default:
-						return errorResponse(c, http.StatusBadRequest, nil)
+					for index, i := range ggRecords {
+						resp[index] = i.JSON()
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"games": resp,
+					})
+				})
+
+				gameGroup.GET("/types", func(c echo.Context) error {
+					factories := games.Factories()
+
+					resp := make([]map[string]interface{}, len(factories))
+
+					for index, f := range factories {
+						resp[index] = map[string]interface{}{
+							"game_type": f.GameType(),
+							"name":      f.Name(),
+							"limits":    f.Limits().JSON(),
+							"schema":    f.Schema(),
+							"events":    games.PayloadSchemas(f),
+						}
 					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"types": resp,
+					})
 				})
 
 				gameGroup.GET("/types/:gameType", func(c echo.Context) error {
-					var gameType games.GameType
-
-					switch c.Param("gameType") {
-					case fmt.Sprintf("%d", games.MoreLess):
-						gameType = games.MoreLess
-					case fmt.Sprintf("%d", games.RockPaperScissors):
-						gameType = games.RockPaperScissors
-					default:
-						return errorResponse(c, http.StatusBadRequest, nil)
+					gameType, errType := games.ParseGameType(c.Param("gameType"))
+					if errType != nil {
+						return errorResponse(c, http.StatusBadRequest, errType)
 					}
 
 					ggRecords, errDb := store.GetActiveGames(c.Request().Context(), gameType)
@@ -265,121 +337,141 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 							return errorResponse(c, http.StatusBadRequest, errDb)
 						}
 
-						return c.JSON(http.StatusOK, echo.Map{
+						resp := echo.Map{
 							"game": rec.JSON(),
-						})
-					})
+						}
 
-					gameGroup.POST("/:gameType", func(c echo.Context) error {
 						user, err := h.GetUserFromCtx(c)
-						if err != nil {
-							logger.Errorw("cant get user from ctx", "error", err.Error())
-							return errorResponse(c, http.StatusUnauthorized, nil)
+						if err == nil && rec.IsPrivate() && rec.GetCreator() == user.GetId() {
+							resp["invite"] = inviteJSON(rec)
 						}
 
-						gameType := c.Param("gameType")
+						return c.JSON(http.StatusOK, resp)
+					})
 
-						switch gameType {
-						case fmt.Sprintf("%d", games.MoreLess):
-							apiCfg := new(MoreLessApiConfig)
-							errCfg := c.Bind(apiCfg)
-							if errCfg != nil {
-								return errorResponse(c, http.StatusBadRequest, errCfg)
-							}
+					gameGroup.GET("/:gameId/verify", func(c echo.Context) error {
+						rec, errDb := store.GetGameById(c.Request().Context(), c.Param("gameId"), database.NewPreload("History", func(db *bun.SelectQuery) *bun.SelectQuery {
+							return db.Order("timestamp")
+						}))
+						if errDb != nil {
+							return errorResponse(c, http.StatusBadRequest, errDb)
+						}
 
-							if apiCfg.Cost < config.MIN_GAME_COST || apiCfg.Cost > config.MAX_GAME_COST {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game cost from %.2f to %d", config.MIN_GAME_COST, config.MAX_GAME_COST))
-							}
+						factory, errFactory := games.GetFactory(rec.GetGameType())
+						if errFactory != nil {
+							return errorResponse(c, http.StatusBadRequest, errFactory)
+						}
 
-							if apiCfg.NumberOfPlayers < config.MIN_PLAYERS || apiCfg.NumberOfPlayers > config.MAX_PLAYERS {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game players cost from %d to %d", 2, 8))
-							}
+						verifier, ok := factory.(games.Verifier)
+						if !ok {
+							return errorResponse(c, http.StatusBadRequest, games.ErrNotVerifiable)
+						}
 
-							if apiCfg.MaxRandom < config.MIN_RANDOM || apiCfg.MaxRandom > config.MAX_RANDOM {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game max random value from %d to %d", 100, 100000))
-							}
+						result, errVerify := verifier.Verify(rec.GetEvents())
+						if errVerify != nil {
+							return errorResponse(c, http.StatusBadRequest, errVerify)
+						}
 
-							gameDuration := time.Duration(apiCfg.Duration) * time.Second
+						return c.JSON(http.StatusOK, echo.Map{
+							"verification": result,
+						})
+					})
 
-							if gameDuration < config.MIN_GAME_DURATION || gameDuration > config.MAX_GAME_DURATION {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game duration value from %s to %s", config.MIN_GAME_DURATION.String(), config.MAX_GAME_DURATION.String()))
-							}
+					gameGroup.GET("/:gameId/replay", func(c echo.Context) error {
+						rec, errDb := store.GetGameById(
+							c.Request().Context(),
+							c.Param("gameId"),
+							database.NewPreload("Players", func(db *bun.SelectQuery) *bun.SelectQuery {
+								return db.Column("id")
+							}),
+							database.NewPreload("History", func(db *bun.SelectQuery) *bun.SelectQuery {
+								return db.Order("timestamp")
+							}),
+							database.NewPreload("Winners"),
+						)
+						if errDb != nil {
+							return errorResponse(c, http.StatusBadRequest, errDb)
+						}
 
-							newGame := morelessGame.New(&games.MoreLessConfig{
-								Cost:            apiCfg.Cost,
-								NumberOfPlayers: apiCfg.NumberOfPlayers,
-								Duration:        gameDuration,
-								WaitAll:         false,
-								MaxRandom:       apiCfg.MaxRandom,
-							}, user.GetId())
-							errGame := runtime.SubscribeOnGame(newGame)
-							if errGame != nil {
-								return errorResponse(c, http.StatusBadRequest, errGame)
-							}
+						return c.JSON(http.StatusOK, echo.Map{
+							"replay": replay.Build(rec),
+						})
+					})
 
-							rec, errDb := store.GetGameById(c.Request().Context(), newGame.GetID(), database.NewPreload("History", func(db *bun.SelectQuery) *bun.SelectQuery {
-								return db.Order("timestamp")
-							}))
-							if errDb != nil {
-								return errorResponse(c, http.StatusBadRequest, errDb)
-							}
+					gameGroup.POST("/:gameType", func(c echo.Context) error {
+						user, err := h.GetUserFromCtx(c)
+						if err != nil {
+							logger.Errorw("cant get user from ctx", "error", err.Error())
+							return errorResponse(c, http.StatusUnauthorized, nil)
+						}
 
-							return c.JSON(http.StatusCreated, echo.Map{
-								"game": rec.JSON(),
-							})
-						case fmt.Sprintf("%d", games.RockPaperScissors):
-							apiCfg := new(RockPaperScissorsConfig)
-							errCfg := c.Bind(apiCfg)
-							if errCfg != nil {
-								return errorResponse(c, http.StatusBadRequest, errCfg)
-							}
+						gameType, errType := games.ParseGameType(c.Param("gameType"))
+						if errType != nil {
+							return errorResponse(c, http.StatusBadRequest, errType)
+						}
 
-							if apiCfg.Cost < config.MIN_GAME_COST || apiCfg.Cost > config.MAX_GAME_COST {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game cost from %.2f to %d", config.MIN_GAME_COST, config.MAX_GAME_COST))
-							}
+						factory, errFactory := games.GetFactory(gameType)
+						if errFactory != nil {
+							return errorResponse(c, http.StatusBadRequest, errFactory)
+						}
 
-							if apiCfg.NumberOfPlayers < config.MIN_PLAYERS || apiCfg.NumberOfPlayers > config.MAX_PLAYERS {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game players cost from %d to %d", 2, 8))
-							}
+						payload, errPayload := readPayload(c)
+						if errPayload != nil {
+							return errorResponse(c, http.StatusBadRequest, errPayload)
+						}
 
-							gameDuration := time.Duration(apiCfg.Duration) * time.Second
+						access := new(GameAccessConfig)
+						errAccess := json.Unmarshal(payload, access)
+						if errAccess != nil {
+							return errorResponse(c, http.StatusBadRequest, errAccess)
+						}
 
-							if gameDuration < config.MIN_GAME_DURATION || gameDuration > config.MAX_GAME_DURATION {
-								return errorResponse(c, http.StatusBadRequest, fmt.Errorf("game duration value from %s to %s", config.MIN_GAME_DURATION.String(), config.MAX_GAME_DURATION.String()))
-							}
+						settlement := new(GameSettlementConfig)
+						errSettlement := json.Unmarshal(payload, settlement)
+						if errSettlement != nil {
+							return errorResponse(c, http.StatusBadRequest, errSettlement)
+						}
 
-							event, errCreateEvent := newChoiceEvent(apiCfg.Choice, user.GetId())
-							if errCreateEvent != nil {
-								return errorResponse(c, http.StatusBadRequest, errCreateEvent)
-							}
+						payoutOption, errPayout := settlementOption(settlement)
+						if errPayout != nil {
+							return errorResponse(c, http.StatusBadRequest, errPayout)
+						}
 
-							newGame, errCreation := rockPaperGame.New(&games.RockPaperConfig{
-								Cost:            apiCfg.Cost,
-								NumberOfPlayers: apiCfg.NumberOfPlayers,
-								Duration:        gameDuration,
-							}, user.GetId(), event)
-							if errCreation != nil {
-								return errorResponse(c, http.StatusBadRequest, errCreation)
+						opts := []database.GameOption{payoutOption}
+						if access.Private {
+							inviteCode, errCode := invite.NewCode(runtime.Env().Random)
+							if errCode != nil {
+								return errorResponse(c, http.StatusBadRequest, errCode)
 							}
+							opts = append(opts, database.Private(inviteCode))
+						}
 
-							errGame := runtime.SubscribeOnGame(newGame)
-							if errGame != nil {
-								return errorResponse(c, http.StatusBadRequest, errGame)
-							}
+						newGame, errCreation := factory.New(runtime.Env(), user.GetId(), payload)
+						if errCreation != nil {
+							return errorResponse(c, http.StatusBadRequest, errCreation)
+						}
 
-							rec, errDb := store.GetGameById(c.Request().Context(), newGame.GetID(), database.NewPreload("History", func(db *bun.SelectQuery) *bun.SelectQuery {
-								return db.Order("timestamp")
-							}))
-							if errDb != nil {
-								return errorResponse(c, http.StatusBadRequest, errDb)
-							}
+						errGame := runtime.SubscribeOnGame(newGame, opts...)
+						if errGame != nil {
+							return errorResponse(c, http.StatusBadRequest, errGame)
+						}
+
+						rec, errDb := store.GetGameById(c.Request().Context(), newGame.GetID(), database.NewPreload("History", func(db *bun.SelectQuery) *bun.SelectQuery {
+							return db.Order("timestamp")
+						}))
+						if errDb != nil {
+							return errorResponse(c, http.StatusBadRequest, errDb)
+						}
 
-							return c.JSON(http.StatusCreated, echo.Map{
-								"game": rec.JSON(),
-							})
-						default:
-							return c.JSON(http.StatusBadRequest, nil)
+						resp := echo.Map{
+							"game": rec.JSON(),
+						}
+
+						if rec.IsPrivate() {
+							resp["invite"] = inviteJSON(rec)
 						}
+
+						return c.JSON(http.StatusCreated, resp)
 					})
 
 					gameGroup.PUT("/:gameId", func(c echo.Context) error {
@@ -396,34 +488,106 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 							return errorResponse(c, http.StatusBadRequest, err)
 						}
 
-						switch gameInstant.GameType() {
-						case games.MoreLess:
+						factory, err := games.GetFactory(gameInstant.GameType())
+						if err != nil {
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						payload, err := readPayload(c)
+						if err != nil {
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						access := new(GameAccessConfig)
+						err = json.Unmarshal(payload, access)
+						if err != nil {
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						inviteCode := access.Invite
+						if inviteCode == "" {
+							inviteCode = c.QueryParam("invite")
+						}
+
+						err = runtime.CheckInvite(c.Request().Context(), gameInstant, inviteCode)
+						if err != nil {
+							return errorResponse(c, http.StatusForbidden, err)
+						}
+
+						event, err := factory.JoinAction(user.GetId(), payload)
+						if err != nil {
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						if event == nil {
 							gameInstant, err = runtime.JoinGame(c.Request().Context(), gameInstant, user.GetId())
 							if err != nil {
 								logger.Errorw("cant join game by id", "error", err.Error())
 								return errorResponse(c, http.StatusBadRequest, err)
 							}
-
-						case games.RockPaperScissors:
-							apiCfg := new(RockPaperScissorsJoinCfg)
-							errCfg := c.Bind(apiCfg)
-							if errCfg != nil {
-								return errorResponse(c, http.StatusBadRequest, errCfg)
-							}
-
-							event, errCreateEvent := newChoiceEvent(apiCfg.Choice, user.GetId())
-							if errCreateEvent != nil {
-								return errorResponse(c, http.StatusBadRequest, errCreateEvent)
-							}
-
+						} else {
 							gameInstant, err = runtime.JoinGameWithAction(c.Request().Context(), gameInstant, user.GetId(), event)
 							if err != nil {
 								logger.Errorw("cant join game with action by id", "error", err.Error())
 								return errorResponse(c, http.StatusBadRequest, err)
 							}
+						}
+
+						gr, err := store.GetGameById(c.Request().Context(), gameInstant.GetID())
+						if err != nil {
+							logger.Errorw("cant get game by id", "error", err.Error())
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						return c.JSON(http.StatusOK, echo.Map{
+							"game": gr.JSON(),
+						})
+					})
+
+					gameGroup.POST("/:gameId/events", func(c echo.Context) error {
+						user, err := h.GetUserFromCtx(c)
+						if err != nil {
+							logger.Errorw("cant get user from ctx", "error", err.Error())
+							return errorResponse(c, http.StatusUnauthorized, nil)
+						}
+
+						gameInstant, err := runtime.GetGame(c.Request().Context(), c.Param("gameId"))
+						if err != nil {
+							logger.Errorw("cant get game by id", "error", err.Error())
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						payload, err := readPayload(c)
+						if err != nil {
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						err = runtime.SendUserEvent(c.Request().Context(), gameInstant, games.NewPlayerEvent(user.GetId(), payload))
+						if err != nil {
+							logger.Errorw("cant send user event", "error", err.Error())
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
+
+						return c.JSON(http.StatusOK, nil)
+					})
+
+					gameGroup.POST("/:gameId/cancel", func(c echo.Context) error {
+						user, err := h.GetUserFromCtx(c)
+						if err != nil {
+							logger.Errorw("cant get user from ctx", "error", err.Error())
+							return errorResponse(c, http.StatusUnauthorized, nil)
+						}
+
+						gameInstant, err := runtime.GetGame(c.Request().Context(), c.Param("gameId"))
+						if err != nil {
+							logger.Errorw("cant get game by id", "error", err.Error())
+							return errorResponse(c, http.StatusBadRequest, err)
+						}
 
-						default:
-							return c.JSON(http.StatusBadRequest, nil)
+						gameInstant, err = runtime.CancelGame(c.Request().Context(), gameInstant, user.GetId())
+						if err != nil {
+							logger.Errorw("cant cancel game by id", "error", err.Error())
+							return errorResponse(c, http.StatusBadRequest, err)
 						}
 
 						gr, err := store.GetGameById(c.Request().Context(), gameInstant.GetID())
@@ -470,6 +634,172 @@ func New(store database.DB, runtime runtime.Runtime, server server.Server, addre
 					})
 				}
 			}
+
+			{
+				matchmakingGroup := apiGroup.Group("/matchmaking")
+
+				matchmakingGroup.POST("/:gameType", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					gameType, errType := games.ParseGameType(c.Param("gameType"))
+					if errType != nil {
+						return errorResponse(c, http.StatusBadRequest, errType)
+					}
+
+					req := new(matchmaking.QuickPlayRequest)
+					if errBind := c.Bind(req); errBind != nil {
+						return errorResponse(c, http.StatusBadRequest, errBind)
+					}
+
+					ticket, errQueue := matchmaker.Enqueue(c.Request().Context(), gameType, user.GetId(), req)
+					if errQueue != nil {
+						return errorResponse(c, http.StatusBadRequest, errQueue)
+					}
+
+					return c.JSON(http.StatusCreated, echo.Map{
+						"ticket": ticket.JSON(),
+					})
+				})
+
+				// wait query param (seconds) hold request until ticket left queue
+				matchmakingGroup.GET("/:ticketId", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					ticket, err := matchmaker.GetTicket(c.Request().Context(), c.Param("ticketId"), user.GetId())
+					if err != nil {
+						return errorResponse(c, http.StatusNotFound, err)
+					}
+
+					wait, _ := strconv.Atoi(c.QueryParam("wait"))
+					if wait > 0 {
+						waitDuration := min(time.Duration(wait)*time.Second, config.QUICK_PLAY_MAX_WAIT)
+						select {
+						case <-ticket.Done():
+						case <-c.Request().Context().Done():
+						case <-time.After(waitDuration):
+						}
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"ticket": ticket.JSON(),
+					})
+				})
+
+				matchmakingGroup.DELETE("/:ticketId", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					ticket, err := matchmaker.Cancel(c.Request().Context(), c.Param("ticketId"), user.GetId())
+					if err != nil {
+						return errorResponse(c, http.StatusBadRequest, err)
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"ticket": ticket.JSON(),
+					})
+				})
+			}
+
+			{
+				tournamentGroup := apiGroup.Group("/tournaments")
+
+				tournamentGroup.GET("", func(c echo.Context) error {
+					records, errDb := store.GetTournaments(c.Request().Context(), database.TournamentRegistration, database.TournamentRunning)
+					if errDb != nil {
+						return errorResponse(c, http.StatusBadRequest, errDb)
+					}
+
+					resp := make([]map[string]interface{}, len(records))
+
+					for index, i := range records {
+						resp[index] = i.JSON()
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"tournaments": resp,
+					})
+				})
+
+				tournamentGroup.GET("/:tournamentId", func(c echo.Context) error {
+					rec, errDb := store.GetTournamentById(c.Request().Context(), c.Param("tournamentId"))
+					if errDb != nil {
+						return errorResponse(c, http.StatusBadRequest, errDb)
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"tournament": rec.JSON(),
+					})
+				})
+
+				tournamentGroup.POST("", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					cfg := new(tournament.TournamentApiConfig)
+					if errBind := c.Bind(cfg); errBind != nil {
+						return errorResponse(c, http.StatusBadRequest, errBind)
+					}
+
+					rec, errCreation := tournaments.Create(c.Request().Context(), user.GetId(), cfg)
+					if errCreation != nil {
+						return errorResponse(c, http.StatusBadRequest, errCreation)
+					}
+
+					return c.JSON(http.StatusCreated, echo.Map{
+						"tournament": rec.JSON(),
+					})
+				})
+
+				tournamentGroup.PUT("/:tournamentId", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					rec, err := tournaments.Register(c.Request().Context(), c.Param("tournamentId"), user.GetId())
+					if err != nil {
+						logger.Errorw("cant register in tournament", "error", err.Error())
+						return errorResponse(c, http.StatusBadRequest, err)
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"tournament": rec.JSON(),
+					})
+				})
+
+				tournamentGroup.DELETE("/:tournamentId", func(c echo.Context) error {
+					user, err := h.GetUserFromCtx(c)
+					if err != nil {
+						logger.Errorw("cant get user from ctx", "error", err.Error())
+						return errorResponse(c, http.StatusUnauthorized, nil)
+					}
+
+					rec, err := tournaments.Left(c.Request().Context(), c.Param("tournamentId"), user.GetId())
+					if err != nil {
+						logger.Errorw("cant left tournament", "error", err.Error())
+						return errorResponse(c, http.StatusBadRequest, err)
+					}
+
+					return c.JSON(http.StatusOK, echo.Map{
+						"tournament": rec.JSON(),
+					})
+				})
+			}
 		}
 	}
 
@@ -0,0 +1,210 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/http_server"
+	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/runtime"
+	"github.com/golang-jwt/jwt"
+	echo "github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+var (
+	ErrInvalidOrigin = errors.New("origin of websocket is not allowed")
+)
+
+// ticketKey sign tickets of streams, differ from key of api token so ticket not accepted by api and token not accepted by streams.
+func ticketKey() []byte {
+	return []byte(config.Config.PayloadSignatureKey + ":ticket")
+}
+
+// streamTicket return short-lived jwt for websocket and event stream which take it in query, long-lived token never in url and logs.
+func streamTicket(userId string) (string, error) {
+	claims := &http_server.JwtCustomClaims{
+		UserId: userId,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(config.WS_TICKET_TTL).Unix(),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ticketKey())
+}
+
+// checkOrigin accept websocket handshake only from pages of the app.
+func checkOrigin(cfg *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(cfg, req)
+	if err != nil {
+		return err
+	}
+
+	app, err := url.Parse(config.Config.AppURL)
+	if err != nil {
+		return err
+	}
+
+	if origin == nil || origin.Scheme != app.Scheme || origin.Host != app.Host {
+		return ErrInvalidOrigin
+	}
+
+	cfg.Origin = origin
+	return nil
+}
+
+// streamMessage is event of the game sent over websocket, fields same as in history of the game.
+type streamMessage struct {
+	GameID    string              `json:"game_id"`
+	Timestamp time.Time           `json:"timestamp"`
+	Type      games.GameEventType `json:"type"`
+	Msg       string              `json:"msg"`
+	MD        games.GameMD        `json:"metadata,omitempty"`
+	Players   []string            `json:"players,omitempty"`
+}
+
+func streamMessageOf(event games.GameEvent) *streamMessage {
+	players := make([]string, len(event.Players()))
+	for i, p := range event.Players() {
+		players[i] = p.GetId()
+	}
+
+	return &streamMessage{
+		GameID:    event.GameId(),
+		Timestamp: event.GetTimeStamp(),
+		Type:      event.GetEventType(),
+		Msg:       event.Msg(),
+		MD:        event.GetMD(),
+		Players:   players,
+	}
+}
+
+// broadcast return true for public events and private events without players, e.g. crash multiplier.
+func broadcast(event games.GameEvent) bool {
+	return event.IsPublic() || len(event.Players()) == 0
+}
+
+// gameStream stream events of active game, client which not read events in time disconnected and should connect again.
+func gameStream(h http_server.Handlers, rt runtime.Runtime, log logger.Logger) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, err := h.GetUserFromCtx(c)
+		if err != nil {
+			log.Errorw("cant get user from ctx", "error", err.Error())
+			return errorResponse(c, http.StatusUnauthorized, nil)
+		}
+
+		game, errGame := rt.GetGame(c.Request().Context(), c.Param("gameId"))
+		if errGame != nil {
+			return errorResponse(c, http.StatusBadRequest, errGame)
+		}
+
+		sub, errSub := rt.Subscribe(game.GetID(), config.HUB_SUBSCRIBER_BUFFER, runtime.Disconnect)
+		if errSub != nil {
+			return errorResponse(c, http.StatusBadRequest, errSub)
+		}
+		defer sub.Close()
+
+		websocket.Server{
+			Handshake: checkOrigin,
+			Handler: func(ws *websocket.Conn) {
+				stream(ws, sub, func(event games.GameEvent) bool {
+					return event.GetEventType() != games.Created && broadcast(event)
+				})
+			},
+		}.ServeHTTP(c.Response(), c.Request())
+
+		return nil
+	}
+}
+
+// lobbyStream push public games of the type right after creation, old notifications dropped for slow client.
+func lobbyStream(h http_server.Handlers, rt runtime.Runtime, log logger.Logger) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		_, err := h.GetUserFromCtx(c)
+		if err != nil {
+			log.Errorw("cant get user from ctx", "error", err.Error())
+			return errorResponse(c, http.StatusUnauthorized, nil)
+		}
+
+		gameType, errType := games.ParseGameType(c.Param("gameType"))
+		if errType != nil {
+			return errorResponse(c, http.StatusBadRequest, errType)
+		}
+
+		_, errFactory := games.GetFactory(gameType)
+		if errFactory != nil {
+			return errorResponse(c, http.StatusBadRequest, errFactory)
+		}
+
+		// lobby subscription receive only created events, updates of running games never take its buffer
+		sub, errSub := rt.Subscribe(runtime.Lobby, config.HUB_SUBSCRIBER_BUFFER, runtime.DropOldest)
+		if errSub != nil {
+			return errorResponse(c, http.StatusBadRequest, errSub)
+		}
+		defer sub.Close()
+
+		websocket.Server{
+			Handshake: checkOrigin,
+			Handler: func(ws *websocket.Conn) {
+				stream(ws, sub, func(event games.GameEvent) bool {
+					// metadata of created event is json of the game record
+					md := event.GetMD()
+					return md["game_type"] == gameType && md["private"] != true
+				})
+			},
+		}.ServeHTTP(c.Response(), c.Request())
+
+		return nil
+	}
+}
+
+// stream send filtered events of the subscription until subscription closed or client gone, messages from client ignored.
+func stream(ws *websocket.Conn, sub runtime.Subscription, filter func(games.GameEvent) bool) {
+	// deadlines of http server still set on upgraded connection
+	_ = ws.SetReadDeadline(time.Time{})
+
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var msg []byte
+		for websocket.Message.Receive(ws, &msg) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case <-gone:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				if errSub := sub.Err(); errSub != nil {
+					_ = send(ws, echo.Map{
+						"error": errSub.Error(),
+					})
+				}
+				return
+			}
+
+			if !filter(event) {
+				continue
+			}
+
+			if send(ws, streamMessageOf(event)) != nil {
+				return
+			}
+		}
+	}
+}
+
+func send(ws *websocket.Conn, v interface{}) error {
+	errDeadline := ws.SetWriteDeadline(time.Now().Add(config.WS_WRITE_TIMEOUT))
+	if errDeadline != nil {
+		return errDeadline
+	}
+
+	return websocket.JSON.Send(ws, v)
+}
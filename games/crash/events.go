@@ -0,0 +1,5 @@
+package crash
+
+// PlayerCashOutEvent send by player during flight, body can be empty.
+type PlayerCashOutEvent struct {
+}
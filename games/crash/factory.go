@@ -0,0 +1,129 @@
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/payout"
+)
+
+var (
+	_ games.Factory            = &factory{}
+	_ games.Verifier           = &factory{}
+	_ games.Restorer           = &factory{}
+	_ games.QuickPlay          = &factory{}
+	_ games.Payloads           = &factory{}
+	_ games.HouseBankedFactory = &factory{}
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+	})
+}
+
+type CrashApiConfig struct {
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	MaxMultiplier   float64 `json:"max_multiplier"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.Crash
+}
+
+func (f *factory) Name() string {
+	return "crash"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &CrashApiConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(CrashApiConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	if config.Config.HouseAccountID == "" {
+		return nil, payout.ErrNoHouseAccount
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	if apiCfg.MaxMultiplier == 0 {
+		apiCfg.MaxMultiplier = config.MAX_CRASH_MULTIPLIER
+	}
+
+	if apiCfg.MaxMultiplier < 2 || apiCfg.MaxMultiplier > config.MAX_CRASH_MULTIPLIER {
+		return nil, fmt.Errorf("game max multiplier from %d to %d", 2, config.MAX_CRASH_MULTIPLIER)
+	}
+
+	return New(&games.CrashConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		MaxMultiplier:   uint64(apiCfg.MaxMultiplier * multiplierBase),
+	}, creator, env.Random, env.Clock)
+}
+
+func (f *factory) QuickPlay(env *games.Env, creator string, cost float64, players uint8) (games.Game, error) {
+	if config.Config.HouseAccountID == "" {
+		return nil, payout.ErrNoHouseAccount
+	}
+
+	return New(&games.CrashConfig{
+		Cost:            cost,
+		NumberOfPlayers: players,
+		Duration:        config.QUICK_PLAY_DURATION,
+		MaxMultiplier:   config.MAX_CRASH_MULTIPLIER * multiplierBase,
+	}, creator, env.Random, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, env.Clock)
+}
+
+func (f *factory) HouseBanked() bool {
+	return true
+}
+
+func (f *factory) JoinAction(_ string, _ json.RawMessage) (games.PlayerEvent, error) {
+	return nil, nil
+}
+
+// Verify recompute crash point from revealed server seed and compare it with published hash and result.
+func (f *factory) Verify(events []*games.RecordedEvent) (games.GameMD, error) {
+	start, result, serverSeed, err := decodeFair(events)
+	if err != nil {
+		return nil, err
+	}
+
+	crashPoint := CrashPoint(serverSeed, result.MaxMultiplier)
+
+	return map[string]interface{}{
+		"valid":            crashPoint == result.CrashPoint,
+		"server_seed":      result.ServerSeed,
+		"server_seed_hash": start.ServerSeedHash,
+		"crash_point":      crashPoint,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package crash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+)
+
+var (
+	ErrInvalidServerSeed = errors.New("server seed not match published hash")
+)
+
+const (
+	serverSeedSize = 32
+	// multiplierBase is 1.00x, all multipliers stored in hundredths
+	multiplierBase = 100
+)
+
+func newServerSeed(src random.Source) ([]byte, error) {
+	seed := make([]byte, serverSeedSize)
+	_, err := src.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+// HashServerSeed return hex of sha256 from server seed, published on game start.
+func HashServerSeed(serverSeed []byte) string {
+	sum := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// CrashPoint return multiplier in hundredths where game crash.
+// h is first 52 bits of HMAC_SHA256(server_seed, "crash"), point is (100 - edge) * 2^52 / (2^52 - h)
+// so chance to reach multiplier x is (1 - edge / 100) / x, points below 1.00x crash instantly. Result limited by max.
+func CrashPoint(serverSeed []byte, max uint64) uint64 {
+	mac := hmac.New(sha256.New, serverSeed)
+	mac.Write([]byte("crash"))
+
+	return crashPointOf(binary.BigEndian.Uint64(mac.Sum(nil)[:8])>>12, max)
+}
+
+// crashPointOf return crash point for 52 bits of the hash.
+func crashPointOf(h uint64, max uint64) uint64 {
+	const e = uint64(1) << 52
+	point := (multiplierBase - config.CRASH_HOUSE_EDGE) * e / (e - h)
+	if point < multiplierBase {
+		return multiplierBase
+	}
+
+	if point > max {
+		return max
+	}
+
+	return point
+}
+
+// Multiplier return multiplier in hundredths after elapsed seconds of flight.
+func Multiplier(elapsed float64, growth float64) uint64 {
+	return uint64(math.Floor(multiplierBase * math.Exp(growth*elapsed)))
+}
+
+// flightTime is inverse of Multiplier, return time of flight needed to reach multiplier in hundredths.
+func flightTime(multiplier uint64, growth float64) time.Duration {
+	return time.Duration(math.Log(float64(multiplier)/multiplierBase) / growth * float64(time.Second))
+}
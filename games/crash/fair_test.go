@@ -0,0 +1,58 @@
+package crash
+
+import (
+	"testing"
+
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashPointOf(t *testing.T) {
+	const e = uint64(1) << 52
+
+	for _, tc := range []struct {
+		name  string
+		h     uint64
+		max   uint64
+		point uint64
+	}{
+		{name: "edge of the house crash instantly", h: 0, max: 10000, point: 100},
+		{name: "below 1.00x crash instantly", h: e / 200, max: 10000, point: 100},
+		{name: "first point above edge", h: e/100 + 1, max: 10000, point: 100},
+		{name: "half of the range", h: e / 2, max: 10000, point: 198},
+		{name: "three quarters of the range", h: e / 4 * 3, max: 10000, point: 396},
+		{name: "capped by max", h: e - 1, max: 10000, point: 10000},
+		{name: "capped by max of the game", h: e / 4 * 3, max: 200, point: 200},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.point, crashPointOf(tc.h, tc.max))
+		})
+	}
+}
+
+func TestCrashPointHouseEdge(t *testing.T) {
+	const rounds = 100000
+	src := random.NewSeeded(1)
+
+	instant, reached := 0, 0
+	for i := 0; i < rounds; i++ {
+		seed, err := newServerSeed(src)
+		require.NoError(t, err)
+
+		point := CrashPoint(seed, 10000)
+		require.GreaterOrEqual(t, point, uint64(multiplierBase))
+		require.LessOrEqual(t, point, uint64(10000))
+		require.Equal(t, point, CrashPoint(seed, 10000))
+
+		if point == multiplierBase {
+			instant += 1
+		}
+		if point >= 2*multiplierBase {
+			reached += 1
+		}
+	}
+
+	// chance to reach x is 0.99 / x: 1% of flights crash below 1.00x by edge of the house, 0.98% below 1.01x by chance
+	require.InDelta(t, 1-0.99/1.01, float64(instant)/rounds, 0.002)
+	require.InDelta(t, 0.495, float64(reached)/rounds, 0.005)
+}
@@ -0,0 +1,389 @@
+package crash
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/google/uuid"
+)
+
+var (
+	_ games.Game         = &crashGame{}
+	_ games.Configurable = &crashGame{}
+	_ games.HouseBanked  = &crashGame{}
+	_ games.Payout       = &winner{}
+
+	ErrNotFlying        = errors.New("cash out possible only during flight")
+	ErrAlreadyCashedOut = errors.New("player already cashed out")
+	ErrNotPlayer        = errors.New("player not part of the game")
+)
+
+// winner is player which cashed out before crash, receive stake multiplied by cash out multiplier.
+type winner struct {
+	games.Player
+	payout float64
+}
+
+func (w *winner) GetPayout() float64 {
+	return w.payout
+}
+
+type crashGame struct {
+	id      string
+	cfg     *games.CrashConfig
+	players map[string]games.Player
+	// cashOuts is multiplier of the player in hundredths
+	cashOuts map[string]uint64
+
+	serverSeed []byte
+	crashPoint uint64
+
+	updates chan games.GameEvent
+
+	createdTime time.Time
+	mutex       sync.Mutex
+
+	clock  clock.Clock
+	timer  clock.Timer
+	ticker clock.Ticker
+	// deadline of the lobby timer, kept in snapshot
+	deadline time.Time
+	restored bool
+
+	flying      bool
+	flightStart time.Time
+	multiplier  uint64
+
+	gameCtx  context.Context
+	gameStop context.CancelFunc
+	finished bool
+	creator  string
+}
+
+func (g *crashGame) AddPlayerWithAction(player games.Player, _ games.PlayerEvent) error {
+	return g.AddPlayer(player)
+}
+
+func (g *crashGame) AddPlayer(p games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.creator == p.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.flying {
+		return games.ErrGameStarted
+	}
+
+	if _, ok := g.players[p.GetId()]; !ok {
+		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
+			return games.ErrMaxPlayer
+		}
+
+		g.players[p.GetId()] = p
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", p.GetId()), true, []games.Player{p}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *crashGame) RemovePlayer(pp games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.flying {
+		return games.ErrGameStarted
+	}
+
+	if g.creator == pp.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if _, ok := g.players[pp.GetId()]; ok {
+		delete(g.players, pp.GetId())
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+// SendUserEvent cash out player with current multiplier.
+func (g *crashGame) SendUserEvent(event games.PlayerEvent) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if !g.flying || g.multiplier >= g.crashPoint {
+		return ErrNotFlying
+	}
+
+	pp, ok := g.players[event.GetPlayerId()]
+	if !ok {
+		return ErrNotPlayer
+	}
+
+	if _, cashedOut := g.cashOuts[pp.GetId()]; cashedOut {
+		return ErrAlreadyCashedOut
+	}
+
+	g.cashOuts[pp.GetId()] = g.multiplier
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("player: %s cash out", pp.GetId()), true, []games.Player{pp}, games.NewPayload(&UpdatePayload{
+		PlayerID:   pp.GetId(),
+		Multiplier: g.multiplier,
+	})), g.snapshot())
+
+	return nil
+}
+
+func (g *crashGame) GetMaxPlayers() uint8 {
+	return g.cfg.NumberOfPlayers
+}
+
+// MaxPayout is every seat cashed out at max multiplier.
+func (g *crashGame) MaxPayout() float64 {
+	return g.cfg.Cost * float64(g.cfg.NumberOfPlayers) * float64(g.cfg.MaxMultiplier) / multiplierBase
+}
+
+func (g *crashGame) GameType() games.GameType {
+	return games.Crash
+}
+
+func (g *crashGame) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+		g.gameCtx, g.gameStop = context.WithCancel(ctx)
+		go g.loop()
+	}
+
+	return nil
+}
+
+func (g *crashGame) getPlayers(withMutex bool) []games.Player {
+	if withMutex {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+	}
+
+	pl := make([]games.Player, len(g.players))
+	i := 0
+	for _, k := range g.players {
+		pl[i] = k
+		i += 1
+	}
+
+	return pl
+}
+
+func (g *crashGame) abort() {
+	g.mutex.Lock()
+	g.finished = true
+	g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
+	g.mutex.Unlock()
+}
+
+func (g *crashGame) loop() {
+	defer func() {
+		close(g.updates)
+		g.gameStop()
+	}()
+
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), games.NewPayload(&StartPayload{
+			ServerSeedHash: HashServerSeed(g.serverSeed),
+		})), g.snapshot())
+	}
+	flying := g.flying
+	g.mutex.Unlock()
+
+	if !flying {
+		select {
+		case <-g.gameCtx.Done():
+			g.abort()
+			return
+		case <-g.timer.C():
+		}
+	}
+
+	g.mutex.Lock()
+	if flying {
+		// flight restored from snapshot continue from stored multiplier, time while server was down not counted
+		g.flightStart = g.clock.Now().Add(-flightTime(g.multiplier, config.CRASH_GROWTH))
+	} else {
+		g.flying = true
+		g.flightStart = g.clock.Now()
+		g.multiplier = multiplierBase
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, "flight is started", true, g.getPlayers(false), games.NewPayload(&UpdatePayload{
+			Multiplier: g.multiplier,
+		})), g.snapshot())
+	}
+	g.ticker = g.clock.NewTicker(config.CRASH_TICK)
+	g.mutex.Unlock()
+
+	defer g.ticker.Stop()
+
+	for {
+		select {
+		case <-g.gameCtx.Done():
+			g.abort()
+			return
+		case now := <-g.ticker.C():
+			g.mutex.Lock()
+			multiplier := Multiplier(now.Sub(g.flightStart).Seconds(), config.CRASH_GROWTH)
+			if multiplier >= g.crashPoint {
+				g.multiplier = g.crashPoint
+				g.mutex.Unlock()
+				g.finish()
+				return
+			}
+
+			g.multiplier = multiplier
+			// ticks are not stored in history, only streamed to subscribers
+			g.updates <- games.NewGameEvent(g.GetID(), games.Update, "multiplier", false, nil, games.NewPayload(&UpdatePayload{
+				Multiplier: multiplier,
+			}))
+			g.mutex.Unlock()
+		}
+	}
+}
+
+// finish always send Winners: game played against house, so players without cash out just lose stake.
+func (g *crashGame) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+func (g *crashGame) GetID() string {
+	return g.id
+}
+
+func (g *crashGame) GetCost() float64 {
+	return g.cfg.Cost
+}
+
+func (g *crashGame) Abort() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		return nil
+	}
+
+	g.timer.Stop()
+	g.gameStop()
+	return nil
+}
+
+type cashOut struct {
+	PlayerID   string  `json:"player_id"`
+	Multiplier uint64  `json:"multiplier"`
+	Payout     float64 `json:"payout"`
+}
+
+func (g *crashGame) GetWinners() ([]games.Player, games.GameMD, error) {
+	ids := make([]string, 0, len(g.cashOuts))
+	for id := range g.cashOuts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	winners := make([]games.Player, len(ids))
+	allCashOuts := make([]*cashOut, len(ids))
+	for i, id := range ids {
+		payout := g.cfg.Cost * float64(g.cashOuts[id]) / multiplierBase
+		winners[i] = &winner{
+			Player: g.players[id],
+			payout: payout,
+		}
+		allCashOuts[i] = &cashOut{
+			PlayerID:   id,
+			Multiplier: g.cashOuts[id],
+			Payout:     payout,
+		}
+	}
+
+	return winners, games.NewPayload(&ResultPayload{
+		CrashPoint:     g.crashPoint,
+		MaxMultiplier:  g.cfg.MaxMultiplier,
+		CashOuts:       allCashOuts,
+		ServerSeed:     hex.EncodeToString(g.serverSeed),
+		ServerSeedHash: HashServerSeed(g.serverSeed),
+	}), nil
+}
+
+func (g *crashGame) GetCreator() string {
+	return g.creator
+}
+
+func (g *crashGame) Updates() <-chan games.GameEvent {
+	return g.updates
+}
+
+func (g *crashGame) GetDuration() time.Duration {
+	return g.cfg.Duration
+}
+
+func (g *crashGame) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"max_multiplier": g.cfg.MaxMultiplier,
+		"growth":         config.CRASH_GROWTH,
+		"tick":           config.CRASH_TICK.Milliseconds(),
+	}
+}
+
+func New(cfg *games.CrashConfig, creator string, src random.Source, clk clock.Clock) (games.Game, error) {
+	serverSeed, errSeed := newServerSeed(src)
+	if errSeed != nil {
+		return nil, errSeed
+	}
+
+	return &crashGame{
+		id:          uuid.New().String(),
+		cfg:         cfg,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
+		creator:     creator,
+		serverSeed:  serverSeed,
+		crashPoint:  CrashPoint(serverSeed, cfg.MaxMultiplier),
+		cashOuts:    make(map[string]uint64),
+		players: map[string]games.Player{
+			creator: &games.BasePlayer{
+				Id: creator,
+			},
+		},
+	}, nil
+}
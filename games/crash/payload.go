@@ -0,0 +1,39 @@
+package crash
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// StartPayload published with Start event, server seed revealed only in result.
+type StartPayload struct {
+	ServerSeedHash string `json:"server_seed_hash"`
+}
+
+// UpdatePayload published on flight start, cash out (player id set) and every tick (not stored in history).
+type UpdatePayload struct {
+	PlayerID string `json:"player_id,omitempty"`
+	// Multiplier in hundredths
+	Multiplier uint64 `json:"multiplier"`
+}
+
+// ResultPayload published with Winners event.
+type ResultPayload struct {
+	CrashPoint     uint64     `json:"crash_point"`
+	MaxMultiplier  uint64     `json:"max_multiplier"`
+	CashOuts       []*cashOut `json:"cash_outs"`
+	ServerSeed     string     `json:"server_seed"`
+	ServerSeedHash string     `json:"server_seed_hash"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Start:
+		return &StartPayload{}
+	case games.Update:
+		return &UpdatePayload{}
+	case games.Winners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+package crash
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// decodeFair return published start, result and revealed server seed checked by hash from start.
+func decodeFair(events []*games.RecordedEvent) (*StartPayload, *ResultPayload, []byte, error) {
+	startEvent := games.LastEvent(events, games.Start)
+	resultEvent := games.LastEvent(events, games.Winners)
+	if startEvent == nil || resultEvent == nil {
+		return nil, nil, nil, games.ErrNotVerifiable
+	}
+
+	start := &StartPayload{}
+	errDecode := startEvent.MD.Decode(start)
+	if errDecode != nil {
+		return nil, nil, nil, errDecode
+	}
+
+	result := &ResultPayload{}
+	errDecode = resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, nil, nil, errDecode
+	}
+
+	if result.MaxMultiplier == 0 {
+		return nil, nil, nil, games.ErrNotVerifiable
+	}
+
+	serverSeed, errSeed := hex.DecodeString(result.ServerSeed)
+	if errSeed != nil {
+		return nil, nil, nil, errSeed
+	}
+
+	if HashServerSeed(serverSeed) != start.ServerSeedHash {
+		return nil, nil, nil, ErrInvalidServerSeed
+	}
+
+	return start, result, serverSeed, nil
+}
+
+// Replay recompute crash point, players who cash out (recorded updates) before it are winners.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	_, result, serverSeed, err := decodeFair(events)
+	if err != nil {
+		return nil, err
+	}
+
+	crashPoint := CrashPoint(serverSeed, result.MaxMultiplier)
+
+	// cash outs recorded as updates with player
+	cashOuts := make(map[string]uint64)
+	for _, event := range events {
+		if event.Type != games.Update {
+			continue
+		}
+
+		update := &UpdatePayload{}
+		errDecode := event.MD.Decode(update)
+		if errDecode != nil {
+			return nil, errDecode
+		}
+
+		if _, exists := cashOuts[update.PlayerID]; update.PlayerID != "" && !exists {
+			cashOuts[update.PlayerID] = update.Multiplier
+		}
+	}
+
+	var winners []string
+	for id, multiplier := range cashOuts {
+		if multiplier < crashPoint {
+			winners = append(winners, id)
+		}
+	}
+	sort.Strings(winners)
+
+	return winners, nil
+}
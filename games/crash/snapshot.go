@@ -0,0 +1,90 @@
+package crash
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+)
+
+type snapshotState struct {
+	Config     *games.CrashConfig `json:"config"`
+	ServerSeed string             `json:"server_seed"`
+	Players    []string           `json:"players"`
+	// Flying set after lobby, Multiplier is multiplier in hundredths at the moment of snapshot
+	Flying     bool              `json:"flying,omitempty"`
+	Multiplier uint64            `json:"multiplier,omitempty"`
+	CashOuts   map[string]uint64 `json:"cash_outs,omitempty"`
+}
+
+// snapshot should be called under mutex.
+func (g *crashGame) snapshot() *games.Snapshot {
+	players := make([]string, 0, len(g.players))
+	for id := range g.players {
+		players = append(players, id)
+	}
+
+	cashOuts := make(map[string]uint64, len(g.cashOuts))
+	for id, multiplier := range g.cashOuts {
+		cashOuts[id] = multiplier
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config:     g.cfg,
+		ServerSeed: hex.EncodeToString(g.serverSeed),
+		Players:    players,
+		Flying:     g.flying,
+		Multiplier: g.multiplier,
+		CashOuts:   cashOuts,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	serverSeed, errSeed := hex.DecodeString(state.ServerSeed)
+	if errSeed != nil {
+		return nil, errSeed
+	}
+
+	g := &crashGame{
+		id:          snapshot.GameID,
+		cfg:         state.Config,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    snapshot.Deadline,
+		restored:    true,
+		creator:     snapshot.Creator,
+		serverSeed:  serverSeed,
+		crashPoint:  CrashPoint(serverSeed, state.Config.MaxMultiplier),
+		cashOuts:    make(map[string]uint64, len(state.CashOuts)),
+		players:     make(map[string]games.Player, len(state.Players)),
+		flying:      state.Flying,
+		multiplier:  state.Multiplier,
+	}
+
+	for _, id := range state.Players {
+		g.players[id] = &games.BasePlayer{
+			Id: id,
+		}
+	}
+
+	for id, multiplier := range state.CashOuts {
+		if _, ok := g.players[id]; !ok {
+			return nil, ErrNotPlayer
+		}
+		g.cashOuts[id] = multiplier
+	}
+
+	if g.flying && g.multiplier < multiplierBase {
+		g.multiplier = multiplierBase
+	}
+
+	return g, nil
+}
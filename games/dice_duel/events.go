@@ -0,0 +1,17 @@
+package dice_duel
+
+type BetKind string
+
+const (
+	// Sum win when sum of dice equal target
+	Sum BetKind = "sum"
+	// Over win when sum of dice greater than target
+	Over BetKind = "over"
+	// Under win when sum of dice less than target
+	Under BetKind = "under"
+)
+
+type PlayerBetEvent struct {
+	Bet    BetKind `json:"bet"`
+	Target uint8   `json:"target"`
+}
@@ -0,0 +1,113 @@
+package dice_duel
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory  = &factory{}
+	_ games.Restorer = &factory{}
+	_ games.Payloads = &factory{}
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+	})
+}
+
+type DiceDuelJoinCfg struct {
+	Bet    BetKind `json:"bet"`
+	Target uint8   `json:"target"`
+}
+
+type DiceDuelConfig struct {
+	Bet             BetKind `json:"bet"`
+	Target          uint8   `json:"target"`
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	Dice            uint8   `json:"dice"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.DiceDuel
+}
+
+func (f *factory) Name() string {
+	return "dice_duel"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &DiceDuelConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(DiceDuelConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	if apiCfg.Dice < 1 || apiCfg.Dice > config.MAX_DICE {
+		return nil, fmt.Errorf("game dice from %d to %d", 1, config.MAX_DICE)
+	}
+
+	event, errEvent := newBetEvent(apiCfg.Bet, apiCfg.Target, creator)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return New(&games.DiceDuelConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		Dice:            apiCfg.Dice,
+	}, creator, event, env.Random, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, env.Random, env.Clock)
+}
+
+func (f *factory) JoinAction(playerID string, payload json.RawMessage) (games.PlayerEvent, error) {
+	joinCfg := new(DiceDuelJoinCfg)
+	errCfg := json.Unmarshal(payload, joinCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	return newBetEvent(joinCfg.Bet, joinCfg.Target, playerID)
+}
+
+func newBetEvent(bet BetKind, target uint8, playerID string) (games.PlayerEvent, error) {
+	payload, err := json.Marshal(&PlayerBetEvent{
+		Bet:    bet,
+		Target: target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return games.NewPlayerEvent(playerID, payload), nil
+}
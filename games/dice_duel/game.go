@@ -0,0 +1,363 @@
+package dice_duel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/google/uuid"
+)
+
+var (
+	_ games.Game         = &diceGame{}
+	_ games.Configurable = &diceGame{}
+
+	ErrIncorrectGame = errors.New("incorrect game")
+	ErrInvalidBet    = errors.New("invalid bet")
+)
+
+const (
+	diceSides = 6
+)
+
+type playerWithBet struct {
+	player games.Player
+	bet    *PlayerBetEvent
+}
+
+type diceGame struct {
+	id      string
+	cfg     *games.DiceDuelConfig
+	players map[string]*playerWithBet
+	src     random.Source
+
+	updates chan games.GameEvent
+
+	createdTime time.Time
+	mutex       sync.Mutex
+
+	clock clock.Clock
+	timer clock.Timer
+	// deadline of the game timer, kept in snapshot
+	deadline time.Time
+	restored bool
+
+	gameCtx  context.Context
+	gameStop context.CancelFunc
+	finished bool
+	creator  string
+}
+
+func validBet(bet *PlayerBetEvent, dice uint8) bool {
+	minSum := dice
+	maxSum := dice * diceSides
+
+	switch bet.Bet {
+	case Sum:
+		return bet.Target >= minSum && bet.Target <= maxSum
+	case Over:
+		return bet.Target >= minSum && bet.Target < maxSum
+	case Under:
+		return bet.Target > minSum && bet.Target <= maxSum
+	default:
+		return false
+	}
+}
+
+func (b *PlayerBetEvent) correct(sum uint8) bool {
+	switch b.Bet {
+	case Sum:
+		return sum == b.Target
+	case Over:
+		return sum > b.Target
+	case Under:
+		return sum < b.Target
+	default:
+		return false
+	}
+}
+
+func getAction(event games.PlayerEvent, dice uint8) (*PlayerBetEvent, error) {
+	betEvent := &PlayerBetEvent{}
+	errEvent := json.Unmarshal(event.GetRawData(), betEvent)
+	if errEvent != nil {
+		return nil, games.ErrInvalidAction
+	}
+
+	if !validBet(betEvent, dice) {
+		return nil, ErrInvalidBet
+	}
+
+	return betEvent, nil
+}
+
+func (g *diceGame) AddPlayer(player games.Player) error {
+	return games.ErrInvalidAction
+}
+
+func (g *diceGame) AddPlayerWithAction(player games.Player, event games.PlayerEvent) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.creator == player.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if _, ok := g.players[player.GetId()]; !ok {
+		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
+			return games.ErrMaxPlayer
+		}
+
+		betEvent, errEvent := getAction(event, g.cfg.Dice)
+		if errEvent != nil {
+			return errEvent
+		}
+
+		g.players[player.GetId()] = &playerWithBet{
+			player: player,
+			bet:    betEvent,
+		}
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", player.GetId()), true, []games.Player{player}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *diceGame) GetID() string {
+	return g.id
+}
+
+func (g *diceGame) GetCost() float64 {
+	return g.cfg.Cost
+}
+
+func (g *diceGame) GameType() games.GameType {
+	return games.DiceDuel
+}
+
+func (g *diceGame) GetDuration() time.Duration {
+	return g.cfg.Duration
+}
+
+func (g *diceGame) RemovePlayer(pp games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.creator == pp.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if _, ok := g.players[pp.GetId()]; ok {
+		delete(g.players, pp.GetId())
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *diceGame) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+		g.gameCtx, g.gameStop = context.WithCancel(ctx)
+		go g.loop()
+	}
+
+	return nil
+}
+
+func (g *diceGame) loop() {
+	defer func() {
+		close(g.updates)
+		g.gameStop()
+	}()
+
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), nil), g.snapshot())
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-g.gameCtx.Done():
+		g.mutex.Lock()
+		g.finished = true
+		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
+		g.mutex.Unlock()
+		return
+	case <-g.timer.C():
+		g.finish()
+	}
+}
+
+func (g *diceGame) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	if len(g.players) == 1 || len(winners) == 0 || len(winners) == len(g.players) {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+		return
+	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+func (g *diceGame) getPlayers(withMutex bool) []games.Player {
+	if withMutex {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+	}
+
+	pl := make([]games.Player, len(g.players))
+	i := 0
+	for _, k := range g.players {
+		pl[i] = k.player
+		i += 1
+	}
+
+	return pl
+}
+
+func (g *diceGame) Abort() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		return nil
+	}
+
+	g.timer.Stop()
+	g.gameStop()
+	return nil
+}
+
+type playerBet struct {
+	PlayerID string  `json:"player_id"`
+	Bet      BetKind `json:"bet"`
+	Target   uint8   `json:"target"`
+	Correct  bool    `json:"correct"`
+}
+
+func (g *diceGame) roll() []uint8 {
+	dice := make([]uint8, g.cfg.Dice)
+	for i := range dice {
+		dice[i] = uint8(random.Uint64n(g.src, diceSides)) + 1
+	}
+
+	return dice
+}
+
+func (g *diceGame) GetWinners() ([]games.Player, games.GameMD, error) {
+	if len(g.players) < 1 {
+		return nil, nil, ErrIncorrectGame
+	}
+
+	dice := g.roll()
+	sum := uint8(0)
+	for _, d := range dice {
+		sum += d
+	}
+
+	var winners []games.Player
+	mdBets := make([]*playerBet, 0, len(g.players))
+	for _, pp := range g.players {
+		correct := pp.bet.correct(sum)
+		if correct {
+			winners = append(winners, pp.player)
+		}
+		mdBets = append(mdBets, &playerBet{
+			PlayerID: pp.player.GetId(),
+			Bet:      pp.bet.Bet,
+			Target:   pp.bet.Target,
+			Correct:  correct,
+		})
+	}
+
+	arrWinners := make([]string, len(winners))
+	for index, player := range winners {
+		arrWinners[index] = player.GetId()
+	}
+
+	mdDice := make([]int, len(dice))
+	for i, d := range dice {
+		mdDice[i] = int(d)
+	}
+
+	return winners, games.NewPayload(&ResultPayload{
+		Dice:    mdDice,
+		Sum:     sum,
+		Players: mdBets,
+		Winners: arrWinners,
+	}), nil
+}
+
+func (g *diceGame) Updates() <-chan games.GameEvent {
+	return g.updates
+}
+
+func (g *diceGame) SendUserEvent(event games.PlayerEvent) error {
+	return games.ErrInvalidAction
+}
+
+func (g *diceGame) GetCreator() string {
+	return g.creator
+}
+
+func (g *diceGame) GetMaxPlayers() uint8 {
+	return g.cfg.NumberOfPlayers
+}
+
+func (g *diceGame) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"dice": g.cfg.Dice,
+	}
+}
+
+func New(cfg *games.DiceDuelConfig, creator string, action games.PlayerEvent, src random.Source, clk clock.Clock) (games.Game, error) {
+	betEvent, errEvent := getAction(action, cfg.Dice)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return &diceGame{
+		id:          uuid.New().String(),
+		cfg:         cfg,
+		src:         src,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
+		creator:     creator,
+		players: map[string]*playerWithBet{
+			creator: {
+				player: &games.BasePlayer{
+					Id: creator,
+				},
+				bet: betEvent,
+			},
+		},
+	}, nil
+}
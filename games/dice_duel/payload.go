@@ -0,0 +1,22 @@
+package dice_duel
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// ResultPayload published with Winners and NoWinners events.
+type ResultPayload struct {
+	Dice    []int        `json:"dice"`
+	Sum     uint8        `json:"sum"`
+	Players []*playerBet `json:"players"`
+	Winners []string     `json:"winners"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Winners, games.NoWinners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
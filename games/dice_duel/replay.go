@@ -0,0 +1,49 @@
+package dice_duel
+
+import (
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// Replay check bets of players against recorded dice.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if resultEvent == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	result := &ResultPayload{}
+	errDecode := resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+
+	sum := uint8(0)
+	for _, d := range result.Dice {
+		sum += uint8(d)
+	}
+
+	var winners []string
+	for _, pb := range result.Players {
+		bet := &PlayerBetEvent{
+			Bet:    pb.Bet,
+			Target: pb.Target,
+		}
+		if bet.correct(sum) {
+			winners = append(winners, pb.PlayerID)
+		}
+	}
+
+	// alone player, nobody or everybody guessed - money back
+	if len(result.Players) == 1 || len(winners) == len(result.Players) {
+		return nil, nil
+	}
+	sort.Strings(winners)
+
+	return winners, nil
+}
@@ -0,0 +1,64 @@
+package dice_duel
+
+import (
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+)
+
+type snapshotState struct {
+	Config *games.DiceDuelConfig `json:"config"`
+	// Bets by player id, contains all players
+	Bets map[string]*PlayerBetEvent `json:"bets"`
+}
+
+// snapshot should be called under mutex.
+func (g *diceGame) snapshot() *games.Snapshot {
+	bets := make(map[string]*PlayerBetEvent, len(g.players))
+	for id, pp := range g.players {
+		bets[id] = pp.bet
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config: g.cfg,
+		Bets:   bets,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, src random.Source, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	g := &diceGame{
+		id:          snapshot.GameID,
+		cfg:         state.Config,
+		src:         src,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    snapshot.Deadline,
+		restored:    true,
+		creator:     snapshot.Creator,
+		players:     make(map[string]*playerWithBet, len(state.Bets)),
+	}
+
+	for id, bet := range state.Bets {
+		if !validBet(bet, g.cfg.Dice) {
+			return nil, ErrInvalidBet
+		}
+
+		g.players[id] = &playerWithBet{
+			player: &games.BasePlayer{
+				Id: id,
+			},
+			bet: bet,
+		}
+	}
+
+	return g, nil
+}
@@ -1,6 +1,7 @@
 package games
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -57,3 +58,23 @@ func NewGameEvent(gameId string, eventType GameEventType, msg string, public boo
 		md:        md,
 	}
 }
+
+type playerEvent struct {
+	playerId string
+	payload  json.RawMessage
+}
+
+func (p *playerEvent) GetPlayerId() string {
+	return p.playerId
+}
+
+func (p *playerEvent) GetRawData() json.RawMessage {
+	return p.payload
+}
+
+func NewPlayerEvent(playerId string, payload json.RawMessage) PlayerEvent {
+	return &playerEvent{
+		playerId: playerId,
+		payload:  payload,
+	}
+}
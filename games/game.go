@@ -12,6 +12,7 @@ var (
 	ErrMaxPlayer       = errors.New("reach max players")
 	ErrGameFinished    = errors.New("game already finished")
 	ErrCreatorCantLeft = errors.New("creator cant left from the game")
+	ErrGameStarted     = errors.New("game already started")
 )
 
 type Player interface {
@@ -28,6 +29,16 @@ func (p *BasePlayer) GetId() string {
 
 type GameMD map[string]interface{}
 
+// Decode convert metadata to typed structure.
+func (md GameMD) Decode(v interface{}) error {
+	raw, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
 type Game interface {
 	GetID() string
 	GetCost() float64
@@ -45,6 +56,41 @@ type Game interface {
 	GetMaxPlayers() uint8
 }
 
+// Configurable implemented by games which expose own settings in game record.
+type Configurable interface {
+	GetSettings() GameMD
+}
+
+// Staked implemented by games where amount locked by player depends on join action.
+type Staked interface {
+	// StakeFor return amount which player lock for join action.
+	StakeFor(action PlayerEvent) (float64, error)
+	// StakeOf return amount currently locked by player in the game.
+	StakeOf(playerID string) float64
+}
+
+// StakeFor return amount which player should lock for join the game with action.
+func StakeFor(game Game, action PlayerEvent) (float64, error) {
+	if staked, ok := game.(Staked); ok && action != nil {
+		return staked.StakeFor(action)
+	}
+
+	return game.GetCost(), nil
+}
+
+// Payout implemented by winners which receive own amount instead of equal part of the bank.
+type Payout interface {
+	// GetPayout return amount which player receive back including own stake.
+	GetPayout() float64
+}
+
+// HouseBanked implemented by games played against house: winners receive games.Payout and rest of the bank goes to house,
+// including rounds without winners.
+type HouseBanked interface {
+	// MaxPayout return most which house can pay to all seats of the game, locked on house account while game played.
+	MaxPayout() float64
+}
+
 type GameEventType int8
 
 type GameType int8
@@ -59,6 +105,10 @@ const (
 	NoWinners
 	Finished
 	Error
+	// Canceled game aborted by creator before any player joined
+	Canceled
+	// Created emitted by runtime only to subscribers of all games when game record stored, metadata is json of the record
+	Created
 )
 
 type GameState int8
@@ -68,6 +118,7 @@ const (
 	GameInProgress
 	GameFinished
 	GameError
+	GameCanceled
 )
 
 type PlayerEvent interface {
@@ -0,0 +1,5 @@
+package jackpot
+
+type PlayerTicketsEvent struct {
+	Tickets uint16 `json:"tickets"`
+}
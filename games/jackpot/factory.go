@@ -0,0 +1,114 @@
+package jackpot
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory  = &factory{}
+	_ games.Restorer = &factory{}
+	_ games.Payloads = &factory{}
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+	})
+}
+
+type JackpotJoinCfg struct {
+	Tickets uint16 `json:"tickets"`
+}
+
+type JackpotConfig struct {
+	Tickets         uint16  `json:"tickets"`
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	MaxTickets      uint16  `json:"max_tickets"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.Jackpot
+}
+
+func (f *factory) Name() string {
+	return "jackpot"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &JackpotConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(JackpotConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	if apiCfg.MaxTickets < 1 || apiCfg.MaxTickets > config.MAX_TICKETS {
+		return nil, fmt.Errorf("game max tickets from %d to %d", 1, config.MAX_TICKETS)
+	}
+
+	event, errEvent := newTicketsEvent(apiCfg.Tickets, creator)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return New(&games.JackpotConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		MaxTickets:      apiCfg.MaxTickets,
+	}, creator, event, env.Random, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, env.Random, env.Clock)
+}
+
+func (f *factory) JoinAction(playerID string, payload json.RawMessage) (games.PlayerEvent, error) {
+	joinCfg := new(JackpotJoinCfg)
+	errCfg := json.Unmarshal(payload, joinCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	if joinCfg.Tickets == 0 {
+		return nil, nil
+	}
+
+	return newTicketsEvent(joinCfg.Tickets, playerID)
+}
+
+func newTicketsEvent(tickets uint16, playerID string) (games.PlayerEvent, error) {
+	payload, err := json.Marshal(&PlayerTicketsEvent{
+		Tickets: tickets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return games.NewPlayerEvent(playerID, payload), nil
+}
@@ -0,0 +1,351 @@
+package jackpot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/google/uuid"
+)
+
+var (
+	_ games.Game         = &jackpotGame{}
+	_ games.Configurable = &jackpotGame{}
+	_ games.Staked       = &jackpotGame{}
+
+	ErrIncorrectGame  = errors.New("incorrect game")
+	ErrInvalidTickets = errors.New("invalid number of tickets")
+)
+
+type playerWithTickets struct {
+	player  games.Player
+	tickets uint16
+}
+
+type jackpotGame struct {
+	id      string
+	cfg     *games.JackpotConfig
+	players map[string]*playerWithTickets
+	src     random.Source
+
+	updates chan games.GameEvent
+
+	createdTime time.Time
+	mutex       sync.Mutex
+
+	clock clock.Clock
+	timer clock.Timer
+	// deadline of the game timer, kept in snapshot
+	deadline time.Time
+	restored bool
+
+	gameCtx  context.Context
+	gameStop context.CancelFunc
+	finished bool
+	creator  string
+}
+
+func getAction(event games.PlayerEvent, maxTickets uint16) (*PlayerTicketsEvent, error) {
+	ticketsEvent := &PlayerTicketsEvent{}
+	errEvent := json.Unmarshal(event.GetRawData(), ticketsEvent)
+	if errEvent != nil {
+		return nil, games.ErrInvalidAction
+	}
+
+	if ticketsEvent.Tickets < 1 || ticketsEvent.Tickets > maxTickets {
+		return nil, ErrInvalidTickets
+	}
+
+	return ticketsEvent, nil
+}
+
+func (g *jackpotGame) StakeFor(action games.PlayerEvent) (float64, error) {
+	ticketsEvent, errEvent := getAction(action, g.cfg.MaxTickets)
+	if errEvent != nil {
+		return 0, errEvent
+	}
+
+	return float64(ticketsEvent.Tickets) * g.cfg.Cost, nil
+}
+
+func (g *jackpotGame) StakeOf(playerID string) float64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	pp, ok := g.players[playerID]
+	if !ok {
+		return 0
+	}
+
+	return float64(pp.tickets) * g.cfg.Cost
+}
+
+func (g *jackpotGame) AddPlayer(player games.Player) error {
+	return g.addTickets(player, 1)
+}
+
+func (g *jackpotGame) AddPlayerWithAction(player games.Player, event games.PlayerEvent) error {
+	ticketsEvent, errEvent := getAction(event, g.cfg.MaxTickets)
+	if errEvent != nil {
+		return errEvent
+	}
+
+	return g.addTickets(player, ticketsEvent.Tickets)
+}
+
+func (g *jackpotGame) addTickets(player games.Player, tickets uint16) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if pp, ok := g.players[player.GetId()]; ok {
+		if uint32(pp.tickets)+uint32(tickets) > uint32(g.cfg.MaxTickets) {
+			return ErrInvalidTickets
+		}
+
+		pp.tickets += tickets
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("player: %s buy %d tickets", player.GetId(), tickets), true, []games.Player{pp.player}, games.NewPayload(&TicketsPayload{
+			PlayerID: player.GetId(),
+			Tickets:  pp.tickets,
+		})), g.snapshot())
+		return nil
+	}
+
+	if len(g.players) >= int(g.cfg.NumberOfPlayers) {
+		return games.ErrMaxPlayer
+	}
+
+	g.players[player.GetId()] = &playerWithTickets{
+		player:  player,
+		tickets: tickets,
+	}
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", player.GetId()), true, []games.Player{player}, games.NewPayload(&TicketsPayload{
+		PlayerID: player.GetId(),
+		Tickets:  tickets,
+	})), g.snapshot())
+
+	return nil
+}
+
+func (g *jackpotGame) GetID() string {
+	return g.id
+}
+
+func (g *jackpotGame) GetCost() float64 {
+	return g.cfg.Cost
+}
+
+func (g *jackpotGame) GameType() games.GameType {
+	return games.Jackpot
+}
+
+func (g *jackpotGame) GetDuration() time.Duration {
+	return g.cfg.Duration
+}
+
+func (g *jackpotGame) RemovePlayer(pp games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.creator == pp.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if _, ok := g.players[pp.GetId()]; ok {
+		delete(g.players, pp.GetId())
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *jackpotGame) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+		g.gameCtx, g.gameStop = context.WithCancel(ctx)
+		go g.loop()
+	}
+
+	return nil
+}
+
+func (g *jackpotGame) loop() {
+	defer func() {
+		close(g.updates)
+		g.gameStop()
+	}()
+
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), nil), g.snapshot())
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-g.gameCtx.Done():
+		g.mutex.Lock()
+		g.finished = true
+		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
+		g.mutex.Unlock()
+		return
+	case <-g.timer.C():
+		g.finish()
+	}
+}
+
+func (g *jackpotGame) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	if len(g.players) == 1 {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+		return
+	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+func (g *jackpotGame) getPlayers(withMutex bool) []games.Player {
+	if withMutex {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+	}
+
+	pl := make([]games.Player, len(g.players))
+	i := 0
+	for _, k := range g.players {
+		pl[i] = k.player
+		i += 1
+	}
+
+	return pl
+}
+
+func (g *jackpotGame) Abort() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		return nil
+	}
+
+	g.timer.Stop()
+	g.gameStop()
+	return nil
+}
+
+type playerTickets struct {
+	PlayerID string `json:"player_id"`
+	Tickets  uint16 `json:"tickets"`
+}
+
+// GetWinners draw one ticket, so chance of the player proportional to his tickets.
+func (g *jackpotGame) GetWinners() ([]games.Player, games.GameMD, error) {
+	if len(g.players) < 1 {
+		return nil, nil, ErrIncorrectGame
+	}
+
+	ids := make([]string, 0, len(g.players))
+	totalTickets := uint64(0)
+	for id, pp := range g.players {
+		ids = append(ids, id)
+		totalTickets += uint64(pp.tickets)
+	}
+	sort.Strings(ids)
+
+	ticket := random.Uint64n(g.src, totalTickets)
+
+	var winner games.Player
+	allTickets := make([]*playerTickets, len(ids))
+	from := uint64(0)
+	for i, id := range ids {
+		pp := g.players[id]
+		if winner == nil && ticket < from+uint64(pp.tickets) {
+			winner = pp.player
+		}
+		from += uint64(pp.tickets)
+		allTickets[i] = &playerTickets{
+			PlayerID: id,
+			Tickets:  pp.tickets,
+		}
+	}
+
+	return []games.Player{winner}, games.NewPayload(&ResultPayload{
+		Tickets:      allTickets,
+		TotalTickets: totalTickets,
+		Ticket:       ticket,
+		Winner:       winner.GetId(),
+	}), nil
+}
+
+func (g *jackpotGame) Updates() <-chan games.GameEvent {
+	return g.updates
+}
+
+func (g *jackpotGame) SendUserEvent(event games.PlayerEvent) error {
+	return games.ErrInvalidAction
+}
+
+func (g *jackpotGame) GetCreator() string {
+	return g.creator
+}
+
+func (g *jackpotGame) GetMaxPlayers() uint8 {
+	return g.cfg.NumberOfPlayers
+}
+
+func (g *jackpotGame) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"max_tickets": g.cfg.MaxTickets,
+	}
+}
+
+func New(cfg *games.JackpotConfig, creator string, action games.PlayerEvent, src random.Source, clk clock.Clock) (games.Game, error) {
+	ticketsEvent, errEvent := getAction(action, cfg.MaxTickets)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return &jackpotGame{
+		id:          uuid.New().String(),
+		cfg:         cfg,
+		src:         src,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
+		creator:     creator,
+		players: map[string]*playerWithTickets{
+			creator: {
+				player: &games.BasePlayer{
+					Id: creator,
+				},
+				tickets: ticketsEvent.Tickets,
+			},
+		},
+	}, nil
+}
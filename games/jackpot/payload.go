@@ -0,0 +1,30 @@
+package jackpot
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// TicketsPayload published when player join (PlayerJoin) or buy more tickets (Update), tickets is total of the player.
+type TicketsPayload struct {
+	PlayerID string `json:"player_id"`
+	Tickets  uint16 `json:"tickets"`
+}
+
+// ResultPayload published with Winners event, ticket is index of drawn ticket.
+type ResultPayload struct {
+	Tickets      []*playerTickets `json:"tickets"`
+	TotalTickets uint64           `json:"total_tickets"`
+	Ticket       uint64           `json:"ticket"`
+	Winner       string           `json:"winner"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.PlayerJoin, games.Update:
+		return &TicketsPayload{}
+	case games.Winners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package jackpot
+
+import (
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// Replay find owner of drawn ticket, tickets numbered by player id order.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if resultEvent == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	result := &ResultPayload{}
+	errDecode := resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+
+	// alone player get money back
+	if len(result.Tickets) < 2 {
+		return nil, nil
+	}
+
+	tickets := append([]*playerTickets{}, result.Tickets...)
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].PlayerID < tickets[j].PlayerID
+	})
+
+	from := uint64(0)
+	for _, pt := range tickets {
+		if result.Ticket < from+uint64(pt.Tickets) {
+			return []string{pt.PlayerID}, nil
+		}
+		from += uint64(pt.Tickets)
+	}
+
+	return nil, games.ErrNotReplayable
+}
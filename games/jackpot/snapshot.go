@@ -0,0 +1,60 @@
+package jackpot
+
+import (
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+)
+
+type snapshotState struct {
+	Config *games.JackpotConfig `json:"config"`
+	// Tickets by player id, contains all players
+	Tickets map[string]uint16 `json:"tickets"`
+}
+
+// snapshot should be called under mutex.
+func (g *jackpotGame) snapshot() *games.Snapshot {
+	tickets := make(map[string]uint16, len(g.players))
+	for id, pp := range g.players {
+		tickets[id] = pp.tickets
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config:  g.cfg,
+		Tickets: tickets,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, src random.Source, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	g := &jackpotGame{
+		id:          snapshot.GameID,
+		cfg:         state.Config,
+		src:         src,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    snapshot.Deadline,
+		restored:    true,
+		creator:     snapshot.Creator,
+		players:     make(map[string]*playerWithTickets, len(state.Tickets)),
+	}
+
+	for id, tickets := range state.Tickets {
+		g.players[id] = &playerWithTickets{
+			player: &games.BasePlayer{
+				Id: id,
+			},
+			tickets: tickets,
+		}
+	}
+
+	return g, nil
+}
@@ -1,26 +1,23 @@
 package games
 
 import (
-	"fmt"
 	"time"
 )
 
 const (
 	MoreLess GameType = iota
 	RockPaperScissors
-)
-
-var (
-	validTypes = []string{fmt.Sprintf("%d", MoreLess)}
+	RockPaperScissorsLizardSpock
+	DiceDuel
+	Jackpot
+	Crash
+	TicTacToe
+	LowestUnique
 )
 
 func ValidGameType(s string) bool {
-	for _, f := range validTypes {
-		if f == s {
-			return true
-		}
-	}
-	return false
+	_, err := ParseGameType(s)
+	return err == nil
 }
 
 type MoreLessConfig struct {
@@ -35,4 +32,45 @@ type RockPaperConfig struct {
 	Cost            float64       `json:"cost"`
 	NumberOfPlayers uint8         `json:"number_of_players"`
 	Duration        time.Duration `json:"duration"`
+	CommitReveal    bool          `json:"commit_reveal"`
+	RevealDuration  time.Duration `json:"reveal_duration"`
+	Rounds          uint8         `json:"rounds"`
+	RoundDuration   time.Duration `json:"round_duration"`
+}
+
+type DiceDuelConfig struct {
+	Cost            float64       `json:"cost"`
+	NumberOfPlayers uint8         `json:"number_of_players"`
+	Duration        time.Duration `json:"duration"`
+	Dice            uint8         `json:"dice"`
+}
+
+type JackpotConfig struct {
+	Cost            float64       `json:"cost"`
+	NumberOfPlayers uint8         `json:"number_of_players"`
+	Duration        time.Duration `json:"duration"`
+	MaxTickets      uint16        `json:"max_tickets"`
+}
+
+type CrashConfig struct {
+	Cost            float64       `json:"cost"`
+	NumberOfPlayers uint8         `json:"number_of_players"`
+	Duration        time.Duration `json:"duration"`
+	// MaxMultiplier in hundredths, crash point never above it
+	MaxMultiplier uint64 `json:"max_multiplier"`
+}
+
+type TurnBasedConfig struct {
+	Cost            float64       `json:"cost"`
+	NumberOfPlayers uint8         `json:"number_of_players"`
+	Duration        time.Duration `json:"duration"`
+	TurnDuration    time.Duration `json:"turn_duration"`
+}
+
+type LowestUniqueConfig struct {
+	Cost            float64       `json:"cost"`
+	NumberOfPlayers uint8         `json:"number_of_players"`
+	Duration        time.Duration `json:"duration"`
+	// MaxNumber players pick number from 1 to MaxNumber
+	MaxNumber uint32 `json:"max_number"`
 }
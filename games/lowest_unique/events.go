@@ -0,0 +1,5 @@
+package lowest_unique
+
+type PlayerNumberEvent struct {
+	Number uint32 `json:"number"`
+}
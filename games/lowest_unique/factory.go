@@ -0,0 +1,110 @@
+package lowest_unique
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory  = &factory{}
+	_ games.Restorer = &factory{}
+	_ games.Payloads = &factory{}
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+	})
+}
+
+type LowestUniqueJoinCfg struct {
+	Number uint32 `json:"number"`
+}
+
+type LowestUniqueConfig struct {
+	Number          uint32  `json:"number"`
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	MaxNumber       uint32  `json:"max_number"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.LowestUnique
+}
+
+func (f *factory) Name() string {
+	return "lowest_unique"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &LowestUniqueConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(LowestUniqueConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	if apiCfg.MaxNumber < config.MIN_UNIQUE_NUMBER || apiCfg.MaxNumber > config.MAX_UNIQUE_NUMBER {
+		return nil, fmt.Errorf("game max number value from %d to %d", config.MIN_UNIQUE_NUMBER, config.MAX_UNIQUE_NUMBER)
+	}
+
+	event, errEvent := newNumberEvent(apiCfg.Number, creator)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return New(&games.LowestUniqueConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		MaxNumber:       apiCfg.MaxNumber,
+	}, creator, event, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, env.Clock)
+}
+
+func (f *factory) JoinAction(playerID string, payload json.RawMessage) (games.PlayerEvent, error) {
+	joinCfg := new(LowestUniqueJoinCfg)
+	errCfg := json.Unmarshal(payload, joinCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	return newNumberEvent(joinCfg.Number, playerID)
+}
+
+func newNumberEvent(number uint32, playerID string) (games.PlayerEvent, error) {
+	payload, err := json.Marshal(&PlayerNumberEvent{
+		Number: number,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return games.NewPlayerEvent(playerID, payload), nil
+}
@@ -0,0 +1,313 @@
+package lowest_unique
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/google/uuid"
+)
+
+var (
+	_ games.Game         = &lowestGame{}
+	_ games.Configurable = &lowestGame{}
+
+	ErrIncorrectGame = errors.New("incorrect game")
+	ErrInvalidNumber = errors.New("invalid number")
+)
+
+type playerWithNumber struct {
+	player games.Player
+	number uint32
+}
+
+type lowestGame struct {
+	id      string
+	cfg     *games.LowestUniqueConfig
+	players map[string]*playerWithNumber
+
+	updates chan games.GameEvent
+
+	createdTime time.Time
+	mutex       sync.Mutex
+
+	clock clock.Clock
+	timer clock.Timer
+	// deadline of the game timer, kept in snapshot
+	deadline time.Time
+	restored bool
+
+	gameCtx  context.Context
+	gameStop context.CancelFunc
+	finished bool
+	creator  string
+}
+
+func getAction(event games.PlayerEvent, maxNumber uint32) (*PlayerNumberEvent, error) {
+	numberEvent := &PlayerNumberEvent{}
+	errEvent := json.Unmarshal(event.GetRawData(), numberEvent)
+	if errEvent != nil {
+		return nil, games.ErrInvalidAction
+	}
+
+	if numberEvent.Number < 1 || numberEvent.Number > maxNumber {
+		return nil, ErrInvalidNumber
+	}
+
+	return numberEvent, nil
+}
+
+func (g *lowestGame) AddPlayer(player games.Player) error {
+	return games.ErrInvalidAction
+}
+
+func (g *lowestGame) AddPlayerWithAction(player games.Player, event games.PlayerEvent) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.creator == player.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if _, ok := g.players[player.GetId()]; !ok {
+		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
+			return games.ErrMaxPlayer
+		}
+
+		numberEvent, errEvent := getAction(event, g.cfg.MaxNumber)
+		if errEvent != nil {
+			return errEvent
+		}
+
+		g.players[player.GetId()] = &playerWithNumber{
+			player: player,
+			number: numberEvent.Number,
+		}
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", player.GetId()), true, []games.Player{player}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *lowestGame) GetID() string {
+	return g.id
+}
+
+func (g *lowestGame) GetCost() float64 {
+	return g.cfg.Cost
+}
+
+func (g *lowestGame) GameType() games.GameType {
+	return games.LowestUnique
+}
+
+func (g *lowestGame) GetDuration() time.Duration {
+	return g.cfg.Duration
+}
+
+func (g *lowestGame) RemovePlayer(pp games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.creator == pp.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if _, ok := g.players[pp.GetId()]; ok {
+		delete(g.players, pp.GetId())
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+func (g *lowestGame) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+		g.gameCtx, g.gameStop = context.WithCancel(ctx)
+		go g.loop()
+	}
+
+	return nil
+}
+
+func (g *lowestGame) loop() {
+	defer func() {
+		close(g.updates)
+		g.gameStop()
+	}()
+
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), nil), g.snapshot())
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-g.gameCtx.Done():
+		g.mutex.Lock()
+		g.finished = true
+		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
+		g.mutex.Unlock()
+		return
+	case <-g.timer.C():
+		g.finish()
+	}
+}
+
+func (g *lowestGame) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	if len(g.players) == 1 || len(winners) == 0 {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+		return
+	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+func (g *lowestGame) getPlayers(withMutex bool) []games.Player {
+	if withMutex {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+	}
+
+	pl := make([]games.Player, len(g.players))
+	i := 0
+	for _, k := range g.players {
+		pl[i] = k.player
+		i += 1
+	}
+
+	return pl
+}
+
+func (g *lowestGame) Abort() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		return nil
+	}
+
+	g.timer.Stop()
+	g.gameStop()
+	return nil
+}
+
+type playerNumber struct {
+	PlayerID string `json:"player_id"`
+	Number   uint32 `json:"number"`
+}
+
+// GetWinners return player with lowest number which was picked only once, empty list when all numbers repeated.
+func (g *lowestGame) GetWinners() ([]games.Player, games.GameMD, error) {
+	if len(g.players) < 1 {
+		return nil, nil, ErrIncorrectGame
+	}
+
+	picks := make(map[uint32][]games.Player)
+	allNumbers := make([]*playerNumber, 0, len(g.players))
+	for _, pp := range g.players {
+		picks[pp.number] = append(picks[pp.number], pp.player)
+		allNumbers = append(allNumbers, &playerNumber{
+			PlayerID: pp.player.GetId(),
+			Number:   pp.number,
+		})
+	}
+
+	sort.Slice(allNumbers, func(i, j int) bool {
+		if allNumbers[i].Number == allNumbers[j].Number {
+			return allNumbers[i].PlayerID < allNumbers[j].PlayerID
+		}
+		return allNumbers[i].Number < allNumbers[j].Number
+	})
+
+	var winners []games.Player
+	winNumber := uint32(0)
+	for _, pn := range allNumbers {
+		if len(picks[pn.Number]) == 1 {
+			winners = picks[pn.Number]
+			winNumber = pn.Number
+			break
+		}
+	}
+
+	return winners, games.NewPayload(&ResultPayload{
+		MaxNumber:     g.cfg.MaxNumber,
+		PlayerNumbers: allNumbers,
+		LowestUnique:  winNumber,
+	}), nil
+}
+
+func (g *lowestGame) Updates() <-chan games.GameEvent {
+	return g.updates
+}
+
+func (g *lowestGame) SendUserEvent(event games.PlayerEvent) error {
+	return games.ErrInvalidAction
+}
+
+func (g *lowestGame) GetCreator() string {
+	return g.creator
+}
+
+func (g *lowestGame) GetMaxPlayers() uint8 {
+	return g.cfg.NumberOfPlayers
+}
+
+func (g *lowestGame) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"max_number": g.cfg.MaxNumber,
+	}
+}
+
+func New(cfg *games.LowestUniqueConfig, creator string, action games.PlayerEvent, clk clock.Clock) (games.Game, error) {
+	numberEvent, errEvent := getAction(action, cfg.MaxNumber)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return &lowestGame{
+		id:          uuid.New().String(),
+		cfg:         cfg,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
+		creator:     creator,
+		players: map[string]*playerWithNumber{
+			creator: {
+				player: &games.BasePlayer{
+					Id: creator,
+				},
+				number: numberEvent.Number,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,21 @@
+package lowest_unique
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// ResultPayload published with Winners and NoWinners events, lowest unique omitted when all numbers repeated.
+type ResultPayload struct {
+	MaxNumber     uint32          `json:"max_number"`
+	PlayerNumbers []*playerNumber `json:"player_numbers"`
+	LowestUnique  uint32          `json:"lowest_unique,omitempty"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Winners, games.NoWinners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package lowest_unique
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// Replay find lowest number picked by exactly one player.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if resultEvent == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	result := &ResultPayload{}
+	errDecode := resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+
+	// alone player get money back
+	if len(result.PlayerNumbers) < 2 {
+		return nil, nil
+	}
+
+	picks := make(map[uint32][]string)
+	for _, pn := range result.PlayerNumbers {
+		picks[pn.Number] = append(picks[pn.Number], pn.PlayerID)
+	}
+
+	var winners []string
+	lowest := uint32(0)
+	for number, players := range picks {
+		if len(players) == 1 && (lowest == 0 || number < lowest) {
+			lowest = number
+			winners = players
+		}
+	}
+
+	return winners, nil
+}
@@ -0,0 +1,58 @@
+package lowest_unique
+
+import (
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+)
+
+type snapshotState struct {
+	Config *games.LowestUniqueConfig `json:"config"`
+	// Numbers by player id, contains all players
+	Numbers map[string]uint32 `json:"numbers"`
+}
+
+// snapshot should be called under mutex.
+func (g *lowestGame) snapshot() *games.Snapshot {
+	numbers := make(map[string]uint32, len(g.players))
+	for id, pp := range g.players {
+		numbers[id] = pp.number
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config:  g.cfg,
+		Numbers: numbers,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	g := &lowestGame{
+		id:          snapshot.GameID,
+		cfg:         state.Config,
+		updates:     make(chan games.GameEvent),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    snapshot.Deadline,
+		restored:    true,
+		creator:     snapshot.Creator,
+		players:     make(map[string]*playerWithNumber, len(state.Numbers)),
+	}
+
+	for id, number := range state.Numbers {
+		g.players[id] = &playerWithNumber{
+			player: &games.BasePlayer{
+				Id: id,
+			},
+			number: number,
+		}
+	}
+
+	return g, nil
+}
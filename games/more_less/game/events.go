@@ -0,0 +1,5 @@
+package game
+
+type PlayerSeedEvent struct {
+	ClientSeed string `json:"client_seed"`
+}
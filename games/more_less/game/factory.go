@@ -0,0 +1,185 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory   = &factory{}
+	_ games.Verifier  = &factory{}
+	_ games.Restorer  = &factory{}
+	_ games.QuickPlay = &factory{}
+	_ games.Hosted    = &factory{}
+	_ games.Payloads  = &factory{}
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+	})
+}
+
+type MoreLessApiConfig struct {
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	MaxRandom       uint32  `json:"max_random"`
+	WaitAll         bool    `json:"wait_all"`
+	ClientSeed      string  `json:"client_seed"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.MoreLess
+}
+
+func (f *factory) Name() string {
+	return "more_less"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &MoreLessApiConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg, cfg, errCfg := f.parse(payload)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	event, errEvent := newSeedEvent(apiCfg.ClientSeed, creator)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return New(cfg, creator, event, env.Random, env.Clock)
+}
+
+func (f *factory) Host(env *games.Env, house string, payload json.RawMessage) (games.Game, error) {
+	_, cfg, errCfg := f.parse(payload)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	return NewHosted(cfg, house, env.Random, env.Clock)
+}
+
+func (f *factory) parse(payload json.RawMessage) (*MoreLessApiConfig, *games.MoreLessConfig, error) {
+	apiCfg := new(MoreLessApiConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, nil, errLimits
+	}
+
+	if apiCfg.MaxRandom < config.MIN_RANDOM || apiCfg.MaxRandom > config.MAX_RANDOM {
+		return nil, nil, fmt.Errorf("game max random value from %d to %d", config.MIN_RANDOM, config.MAX_RANDOM)
+	}
+
+	return apiCfg, &games.MoreLessConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		WaitAll:         apiCfg.WaitAll,
+		MaxRandom:       apiCfg.MaxRandom,
+	}, nil
+}
+
+func (f *factory) JoinAction(playerID string, payload json.RawMessage) (games.PlayerEvent, error) {
+	seedEvent := new(PlayerSeedEvent)
+	errEvent := json.Unmarshal(payload, seedEvent)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	if seedEvent.ClientSeed == "" {
+		return nil, nil
+	}
+
+	return newSeedEvent(seedEvent.ClientSeed, playerID)
+}
+
+func (f *factory) QuickPlay(env *games.Env, creator string, cost float64, players uint8) (games.Game, error) {
+	return New(&games.MoreLessConfig{
+		Cost:            cost,
+		NumberOfPlayers: players,
+		Duration:        config.QUICK_PLAY_DURATION,
+		WaitAll:         true,
+		MaxRandom:       config.MAX_RANDOM,
+	}, creator, nil, env.Random, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, env.Clock)
+}
+
+type verifiedNumber struct {
+	PlayerID       string `json:"player_id"`
+	ClientSeed     string `json:"client_seed"`
+	Number         uint64 `json:"number"`
+	ExpectedNumber uint64 `json:"expected_number"`
+	Valid          bool   `json:"valid"`
+}
+
+// Verify recompute every player number from revealed server seed and compare it with published hash and result.
+func (f *factory) Verify(events []*games.RecordedEvent) (games.GameMD, error) {
+	start, result, serverSeed, err := decodeFair(events)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := true
+	maxNumber := uint64(0)
+	numbers := make([]*verifiedNumber, len(result.PlayerNumbers))
+	for i, pn := range result.PlayerNumbers {
+		expected := PlayerNumber(serverSeed, pn.ClientSeed, pn.PlayerID, uint64(result.MaxRandom))
+		if maxNumber <= expected {
+			maxNumber = expected
+		}
+		numbers[i] = &verifiedNumber{
+			PlayerID:       pn.PlayerID,
+			ClientSeed:     pn.ClientSeed,
+			Number:         pn.Number,
+			ExpectedNumber: expected,
+			Valid:          expected == pn.Number,
+		}
+		valid = valid && numbers[i].Valid
+	}
+
+	return map[string]interface{}{
+		"valid":            valid && maxNumber == result.MaxNumber,
+		"server_seed":      result.ServerSeed,
+		"server_seed_hash": start.ServerSeedHash,
+		"max_number":       maxNumber,
+		"player_numbers":   numbers,
+	}, nil
+}
+
+func newSeedEvent(clientSeed string, playerID string) (games.PlayerEvent, error) {
+	payload, err := json.Marshal(&PlayerSeedEvent{
+		ClientSeed: clientSeed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return games.NewPlayerEvent(playerID, payload), nil
+}
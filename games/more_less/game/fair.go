@@ -0,0 +1,53 @@
+package game
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/PxyUp/ton_games_example/pkg/random"
+)
+
+var (
+	ErrInvalidServerSeed = errors.New("server seed not match published hash")
+)
+
+const (
+	serverSeedSize = 32
+)
+
+func newServerSeed(src random.Source) ([]byte, error) {
+	seed := make([]byte, serverSeedSize)
+	_, err := src.Read(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+// HashServerSeed return hex of sha256 from server seed, published on game start.
+func HashServerSeed(serverSeed []byte) string {
+	sum := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// PlayerNumber return number of player between 0..max-1.
+// Number is first 8 bytes of HMAC_SHA256(server_seed, "client_seed:player_id:nonce") as big endian uint64,
+// nonce starts from 0 and increased while value fall into biased tail of uint64 range.
+func PlayerNumber(serverSeed []byte, clientSeed string, playerID string, max uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%max
+
+	for nonce := uint64(0); ; nonce++ {
+		mac := hmac.New(sha256.New, serverSeed)
+		mac.Write([]byte(fmt.Sprintf("%s:%s:%d", clientSeed, playerID, nonce)))
+		value := binary.BigEndian.Uint64(mac.Sum(nil)[:8])
+		if value < limit {
+			return value % max
+		}
+	}
+}
@@ -2,15 +2,24 @@ package game
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
 	"github.com/PxyUp/ton_games_example/pkg/random"
 	"github.com/google/uuid"
 )
 
+var (
+	_ games.Game         = &game{}
+	_ games.Configurable = &game{}
+)
+
 type State int8
 
 type game struct {
@@ -19,10 +28,21 @@ type game struct {
 	players map[string]games.Player
 	id      string
 
+	serverSeed  []byte
+	clientSeeds map[string]string
+
 	createdTime time.Time
 	mutex       sync.Mutex
 
-	ticker *time.Ticker
+	clock clock.Clock
+	timer clock.Timer
+	// deadline of the game timer, kept in snapshot
+	deadline time.Time
+	restored bool
+	// full closed when all seats taken in WaitAll mode
+	full chan struct{}
+	// lobbyClosed set with close of full, players can not join or left after it
+	lobbyClosed bool
 
 	gameCtx  context.Context
 	gameStop context.CancelFunc
@@ -30,8 +50,27 @@ type game struct {
 	creator  string
 }
 
+func getAction(event games.PlayerEvent) (*PlayerSeedEvent, error) {
+	seedEvent := &PlayerSeedEvent{}
+	if event == nil {
+		return seedEvent, nil
+	}
+
+	errEvent := json.Unmarshal(event.GetRawData(), seedEvent)
+	if errEvent != nil {
+		return nil, games.ErrInvalidAction
+	}
+
+	return seedEvent, nil
+}
+
 func (g *game) AddPlayerWithAction(player games.Player, event games.PlayerEvent) error {
-	return games.ErrInvalidAction
+	seedEvent, errEvent := getAction(event)
+	if errEvent != nil {
+		return errEvent
+	}
+
+	return g.addPlayer(player, seedEvent.ClientSeed)
 }
 
 func (g *game) SendUserEvent(_ games.PlayerEvent) error {
@@ -54,19 +93,28 @@ func (g *game) RemovePlayer(pp games.Player) error {
 		return games.ErrGameFinished
 	}
 
+	if g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
 	if g.creator == pp.GetId() {
 		return games.ErrCreatorCantLeft
 	}
 
 	if _, ok := g.players[pp.GetId()]; ok {
 		delete(g.players, pp.GetId())
-		g.updates <- games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil)
+		delete(g.clientSeeds, pp.GetId())
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
 	}
 
 	return nil
 }
 
 func (g *game) AddPlayer(p games.Player) error {
+	return g.addPlayer(p, "")
+}
+
+func (g *game) addPlayer(p games.Player, clientSeed string) error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
 
@@ -78,23 +126,42 @@ func (g *game) AddPlayer(p games.Player) error {
 		return games.ErrGameFinished
 	}
 
+	if g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
 	if _, ok := g.players[p.GetId()]; !ok {
 		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
 			return games.ErrMaxPlayer
 		}
 
 		g.players[p.GetId()] = p
-		g.updates <- games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", p.GetId()), true, []games.Player{p}, nil)
+		g.clientSeeds[p.GetId()] = clientSeed
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", p.GetId()), true, []games.Player{p}, nil), g.snapshot())
+
+		if g.cfg.WaitAll && len(g.players) == int(g.cfg.NumberOfPlayers) {
+			g.closeLobby()
+		}
 	}
 
 	return nil
 }
 
+// closeLobby close full only once, should be called under mutex.
+func (g *game) closeLobby() {
+	if g.lobbyClosed {
+		return
+	}
+
+	g.lobbyClosed = true
+	close(g.full)
+}
+
 func (g *game) Start(ctx context.Context) error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
-	if g.ticker == nil {
-		g.ticker = time.NewTicker(g.cfg.Duration)
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
 		g.gameCtx, g.gameStop = context.WithCancel(ctx)
 		go g.loop()
 	}
@@ -124,37 +191,76 @@ func (g *game) loop() {
 		g.gameStop()
 	}()
 
-	g.updates <- games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(true), nil)
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), games.NewPayload(&StartPayload{
+			ServerSeedHash: HashServerSeed(g.serverSeed),
+		})), g.snapshot())
+	}
+	g.mutex.Unlock()
 
 	select {
 	case <-g.gameCtx.Done():
 		g.mutex.Lock()
 		g.finished = true
-		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(true), nil)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
 		g.mutex.Unlock()
 		return
-	case <-g.ticker.C:
-		g.mutex.Lock()
+	case <-g.timer.C():
+		g.finish()
+	case <-g.full:
+		g.timer.Stop()
+		g.finish()
+	}
+}
 
-		g.finished = true
-		winners, md, err := g.GetWinners()
-		if err != nil {
-			g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
-			g.mutex.Unlock()
-			return
-		}
+func (g *game) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 
-		if len(g.players) == 1 && len(winners) == 1 && winners[0].GetId() == g.creator {
-			g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
-			g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
-			g.mutex.Unlock()
-			return
-		}
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
 
-		g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	// alone creator or single player of house game
+	if len(g.players) < 2 {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
 		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
-		g.mutex.Unlock()
+		return
 	}
+
+	g.updates <- games.WithRanking(games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md), g.ranking())
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+// ranking group players by number from biggest, should be called under mutex.
+func (g *game) ranking() [][]games.Player {
+	players := g.getPlayers(false)
+	numbers := make(map[string]uint64, len(players))
+	for _, p := range players {
+		numbers[p.GetId()] = PlayerNumber(g.serverSeed, g.clientSeeds[p.GetId()], p.GetId(), uint64(g.cfg.MaxRandom))
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		if numbers[players[i].GetId()] != numbers[players[j].GetId()] {
+			return numbers[players[i].GetId()] > numbers[players[j].GetId()]
+		}
+		return players[i].GetId() < players[j].GetId()
+	})
+
+	var ranking [][]games.Player
+	for i, p := range players {
+		if i > 0 && numbers[p.GetId()] == numbers[players[i-1].GetId()] {
+			ranking[len(ranking)-1] = append(ranking[len(ranking)-1], p)
+			continue
+		}
+		ranking = append(ranking, []games.Player{p})
+	}
+
+	return ranking
 }
 
 func (g *game) GetID() string {
@@ -168,18 +274,19 @@ func (g *game) GetCost() float64 {
 func (g *game) Abort() error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
-	if g.ticker == nil {
+	if g.timer == nil {
 		return nil
 	}
 
-	g.ticker.Stop()
+	g.timer.Stop()
 	g.gameStop()
 	return nil
 }
 
 type playerNumber struct {
-	Number   uint64 `json:"number"`
-	PlayerID string `json:"player_id"`
+	Number     uint64 `json:"number"`
+	PlayerID   string `json:"player_id"`
+	ClientSeed string `json:"client_seed"`
 }
 
 func (g *game) GetWinners() ([]games.Player, games.GameMD, error) {
@@ -190,7 +297,7 @@ func (g *game) GetWinners() ([]games.Player, games.GameMD, error) {
 	pNumbers := make([]uint64, len(players))
 
 	for i := range players {
-		newRandom := random.GetRandom(uint64(g.cfg.MaxRandom))
+		newRandom := PlayerNumber(g.serverSeed, g.clientSeeds[players[i].GetId()], players[i].GetId(), uint64(g.cfg.MaxRandom))
 		pNumbers[i] = newRandom
 		if maxNumber <= newRandom {
 			maxNumber = newRandom
@@ -209,15 +316,19 @@ func (g *game) GetWinners() ([]games.Player, games.GameMD, error) {
 
 	for i := range players {
 		allNumbers[i] = &playerNumber{
-			Number:   pNumbers[i],
-			PlayerID: players[i].GetId(),
+			Number:     pNumbers[i],
+			PlayerID:   players[i].GetId(),
+			ClientSeed: g.clientSeeds[players[i].GetId()],
 		}
 	}
 
-	return winners, map[string]interface{}{
-		"max_number":     maxNumber,
-		"player_numbers": allNumbers,
-	}, nil
+	return winners, games.NewPayload(&ResultPayload{
+		MaxNumber:      maxNumber,
+		MaxRandom:      g.cfg.MaxRandom,
+		PlayerNumbers:  allNumbers,
+		ServerSeed:     hex.EncodeToString(g.serverSeed),
+		ServerSeedHash: HashServerSeed(g.serverSeed),
+	}), nil
 }
 
 func (g *game) GetCreator() string {
@@ -232,17 +343,54 @@ func (g *game) GetDuration() time.Duration {
 	return g.cfg.Duration
 }
 
-func New(cfg *games.MoreLessConfig, creator string) games.Game {
+func (g *game) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"wait_all":   g.cfg.WaitAll,
+		"max_random": g.cfg.MaxRandom,
+	}
+}
+
+func New(cfg *games.MoreLessConfig, creator string, action games.PlayerEvent, src random.Source, clk clock.Clock) (games.Game, error) {
+	seedEvent, errEvent := getAction(action)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	g, err := newGame(cfg, creator, src, clk)
+	if err != nil {
+		return nil, err
+	}
+
+	g.players[creator] = &games.BasePlayer{
+		Id: creator,
+	}
+	g.clientSeeds[creator] = seedEvent.ClientSeed
+
+	return g, nil
+}
+
+// NewHosted create game of the house, all seats are free for players.
+func NewHosted(cfg *games.MoreLessConfig, house string, src random.Source, clk clock.Clock) (games.Game, error) {
+	return newGame(cfg, house, src, clk)
+}
+
+func newGame(cfg *games.MoreLessConfig, creator string, src random.Source, clk clock.Clock) (*game, error) {
+	serverSeed, errSeed := newServerSeed(src)
+	if errSeed != nil {
+		return nil, errSeed
+	}
+
 	return &game{
 		id:          uuid.New().String(),
 		cfg:         cfg,
 		updates:     make(chan games.GameEvent),
-		createdTime: time.Now(),
+		full:        make(chan struct{}),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
 		creator:     creator,
-		players: map[string]games.Player{
-			creator: &games.BasePlayer{
-				Id: creator,
-			},
-		},
-	}
+		serverSeed:  serverSeed,
+		clientSeeds: make(map[string]string),
+		players:     make(map[string]games.Player),
+	}, nil
 }
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/stretchr/testify/require"
+)
+
+const gameDuration = 30 * time.Second
+
+func newTestGame(t *testing.T, players uint8, waitAll bool) (*game, *clock.Manual, <-chan games.GameEvent) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	g, err := New(&games.MoreLessConfig{
+		Cost:            1,
+		NumberOfPlayers: players,
+		Duration:        gameDuration,
+		WaitAll:         waitAll,
+		MaxRandom:       100,
+	}, "creator", nil, random.NewSeeded(1), clk)
+	require.NoError(t, err)
+
+	// updates not buffered, game block on send until event read
+	events := make(chan games.GameEvent, 16)
+	go func() {
+		defer close(events)
+		for event := range g.Updates() {
+			events <- event
+		}
+	}()
+
+	return g.(*game), clk, events
+}
+
+func next(t *testing.T, events <-chan games.GameEvent) games.GameEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "updates closed")
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event from game")
+		return nil
+	}
+}
+
+func requireClosed(t *testing.T, events <-chan games.GameEvent) {
+	select {
+	case event, ok := <-events:
+		require.False(t, ok, "unexpected event %v", event)
+	case <-time.After(time.Second):
+		require.FailNow(t, "updates not closed")
+	}
+}
+
+func TestGameWinnersAfterDuration(t *testing.T) {
+	g, clk, events := newTestGame(t, 2, false)
+	require.NoError(t, g.AddPlayer(&games.BasePlayer{Id: "player"}))
+	require.Equal(t, games.PlayerJoin, next(t, events).GetEventType())
+
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	clk.Advance(gameDuration - time.Second)
+	require.Equal(t, 1, clk.Timers())
+
+	clk.Advance(time.Second)
+	winners := next(t, events)
+	require.Equal(t, games.Winners, winners.GetEventType())
+	require.NotEmpty(t, winners.Players())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+
+	require.ErrorIs(t, g.AddPlayer(&games.BasePlayer{Id: "late"}), games.ErrGameFinished)
+}
+
+func TestGameAloneCreatorNoWinners(t *testing.T) {
+	g, clk, events := newTestGame(t, 2, false)
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	clk.Advance(gameDuration)
+	require.Equal(t, games.NoWinners, next(t, events).GetEventType())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+}
+
+func TestGameWaitAllFinishWhenFull(t *testing.T) {
+	g, clk, events := newTestGame(t, 2, true)
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	require.NoError(t, g.AddPlayer(&games.BasePlayer{Id: "player"}))
+	require.Equal(t, games.PlayerJoin, next(t, events).GetEventType())
+	require.Equal(t, games.Winners, next(t, events).GetEventType())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+	require.Equal(t, 0, clk.Timers())
+}
+
+func TestGameAbort(t *testing.T) {
+	g, clk, events := newTestGame(t, 2, false)
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	require.NoError(t, g.Abort())
+	require.Equal(t, games.Abort, next(t, events).GetEventType())
+	requireClosed(t, events)
+
+	clk.Advance(gameDuration)
+	require.Equal(t, 0, clk.Timers())
+}
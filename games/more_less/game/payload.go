@@ -0,0 +1,30 @@
+package game
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// StartPayload published with Start event, server seed revealed only in result.
+type StartPayload struct {
+	ServerSeedHash string `json:"server_seed_hash"`
+}
+
+// ResultPayload published with Winners and NoWinners events.
+type ResultPayload struct {
+	MaxNumber      uint64          `json:"max_number"`
+	MaxRandom      uint32          `json:"max_random"`
+	PlayerNumbers  []*playerNumber `json:"player_numbers"`
+	ServerSeed     string          `json:"server_seed"`
+	ServerSeedHash string          `json:"server_seed_hash"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Start:
+		return &StartPayload{}
+	case games.Winners, games.NoWinners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// decodeFair return published start, result and revealed server seed checked by hash from start.
+func decodeFair(events []*games.RecordedEvent) (*StartPayload, *ResultPayload, []byte, error) {
+	startEvent := games.LastEvent(events, games.Start)
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if startEvent == nil || resultEvent == nil {
+		return nil, nil, nil, games.ErrNotVerifiable
+	}
+
+	start := &StartPayload{}
+	errDecode := startEvent.MD.Decode(start)
+	if errDecode != nil {
+		return nil, nil, nil, errDecode
+	}
+
+	result := &ResultPayload{}
+	errDecode = resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, nil, nil, errDecode
+	}
+
+	serverSeed, errSeed := hex.DecodeString(result.ServerSeed)
+	if errSeed != nil {
+		return nil, nil, nil, errSeed
+	}
+
+	if HashServerSeed(serverSeed) != start.ServerSeedHash {
+		return nil, nil, nil, ErrInvalidServerSeed
+	}
+
+	if result.MaxRandom == 0 {
+		return nil, nil, nil, games.ErrNotVerifiable
+	}
+
+	return start, result, serverSeed, nil
+}
+
+// Replay recompute numbers of players from revealed server seed and client seeds, biggest number win.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	_, result, serverSeed, err := decodeFair(events)
+	if err != nil {
+		return nil, err
+	}
+
+	// alone player get money back
+	if len(result.PlayerNumbers) < 2 {
+		return nil, nil
+	}
+
+	numbers := make(map[string]uint64, len(result.PlayerNumbers))
+	maxNumber := uint64(0)
+	for _, pn := range result.PlayerNumbers {
+		numbers[pn.PlayerID] = PlayerNumber(serverSeed, pn.ClientSeed, pn.PlayerID, uint64(result.MaxRandom))
+		if maxNumber <= numbers[pn.PlayerID] {
+			maxNumber = numbers[pn.PlayerID]
+		}
+	}
+
+	var winners []string
+	for id, number := range numbers {
+		if number == maxNumber {
+			winners = append(winners, id)
+		}
+	}
+	sort.Strings(winners)
+
+	return winners, nil
+}
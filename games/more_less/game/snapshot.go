@@ -0,0 +1,70 @@
+package game
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+)
+
+type snapshotState struct {
+	Config     *games.MoreLessConfig `json:"config"`
+	ServerSeed string                `json:"server_seed"`
+	// ClientSeeds by player id, contains all players
+	ClientSeeds map[string]string `json:"client_seeds"`
+}
+
+// snapshot should be called under mutex.
+func (g *game) snapshot() *games.Snapshot {
+	clientSeeds := make(map[string]string, len(g.clientSeeds))
+	for id, seed := range g.clientSeeds {
+		clientSeeds[id] = seed
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config:      g.cfg,
+		ServerSeed:  hex.EncodeToString(g.serverSeed),
+		ClientSeeds: clientSeeds,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	serverSeed, errSeed := hex.DecodeString(state.ServerSeed)
+	if errSeed != nil {
+		return nil, errSeed
+	}
+
+	g := &game{
+		id:          snapshot.GameID,
+		cfg:         state.Config,
+		updates:     make(chan games.GameEvent),
+		full:        make(chan struct{}),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    snapshot.Deadline,
+		restored:    true,
+		creator:     snapshot.Creator,
+		serverSeed:  serverSeed,
+		clientSeeds: state.ClientSeeds,
+		players:     make(map[string]games.Player, len(state.ClientSeeds)),
+	}
+
+	for id := range state.ClientSeeds {
+		g.players[id] = &games.BasePlayer{
+			Id: id,
+		}
+	}
+
+	if g.cfg.WaitAll && len(g.players) == int(g.cfg.NumberOfPlayers) {
+		g.closeLobby()
+	}
+
+	return g, nil
+}
@@ -0,0 +1,163 @@
+package games
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PayloadVersion is schema version of typed event payloads, increased on incompatible change of any payload.
+const PayloadVersion = 1
+
+var (
+	ErrUnknownEventType = errors.New("unknown event type")
+	ErrUntypedPayload   = errors.New("event has no typed payload")
+)
+
+var eventTypeNames = map[GameEventType]string{
+	Start:      "start",
+	Update:     "update",
+	PlayerJoin: "player_join",
+	PlayerLeft: "player_left",
+	Abort:      "abort",
+	Winners:    "winners",
+	NoWinners:  "no_winners",
+	Finished:   "finished",
+	Error:      "error",
+	Canceled:   "canceled",
+	Created:    "created",
+}
+
+// EventTypes return all event types ordered by value.
+func EventTypes() []GameEventType {
+	list := make([]GameEventType, len(eventTypeNames))
+	for t := range eventTypeNames {
+		list[t] = t
+	}
+
+	return list
+}
+
+// String return stable name of event type used in json.
+func (t GameEventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(t))
+}
+
+func (t GameEventType) MarshalText() ([]byte, error) {
+	if _, ok := eventTypeNames[t]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownEventType, t)
+	}
+
+	return []byte(t.String()), nil
+}
+
+func (t *GameEventType) UnmarshalText(text []byte) error {
+	parsed, err := ParseGameEventType(string(text))
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
+// ParseGameEventType convert name of event type to GameEventType, numeric value accepted for old clients.
+func ParseGameEventType(s string) (GameEventType, error) {
+	for t, name := range eventTypeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+
+	value, err := strconv.ParseInt(s, 10, 8)
+	if err != nil {
+		return 0, ErrUnknownEventType
+	}
+
+	if _, ok := eventTypeNames[GameEventType(value)]; !ok {
+		return 0, ErrUnknownEventType
+	}
+
+	return GameEventType(value), nil
+}
+
+// Payloads implemented by factories of games which publish typed metadata of events.
+type Payloads interface {
+	// Payload return pointer to new typed payload of the event type, nil if event type has no metadata.
+	Payload(eventType GameEventType) interface{}
+}
+
+// NewPayload convert typed payload to metadata of the event, schema version stored in "version" key.
+func NewPayload(payload interface{}) GameMD {
+	md := GameMD{}
+	raw, err := json.Marshal(payload)
+	if err == nil {
+		_ = json.Unmarshal(raw, &md)
+	}
+	md["version"] = PayloadVersion
+
+	return md
+}
+
+// Version return schema version of metadata, 0 for metadata recorded before typed payloads.
+func (md GameMD) Version() int {
+	switch v := md["version"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case json.Number:
+		version, _ := v.Int64()
+		return int(version)
+	default:
+		return 0
+	}
+}
+
+// DecodePayload decode metadata of recorded event to typed payload of the game type.
+// Events without metadata or recorded before typed payloads return ErrUntypedPayload.
+func DecodePayload(gameType GameType, event *RecordedEvent) (interface{}, error) {
+	factory, err := GetFactory(gameType)
+	if err != nil {
+		return nil, err
+	}
+
+	payloads, ok := factory.(Payloads)
+	if !ok || event.MD.Version() == 0 {
+		return nil, ErrUntypedPayload
+	}
+
+	payload := payloads.Payload(event.Type)
+	if payload == nil {
+		return nil, ErrUntypedPayload
+	}
+
+	err = event.MD.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// PayloadSchemas return example of typed payload by event type name, used by clients to parse history.
+func PayloadSchemas(factory Factory) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	payloads, ok := factory.(Payloads)
+	if !ok {
+		return schemas
+	}
+
+	for _, t := range EventTypes() {
+		if payload := payloads.Payload(t); payload != nil {
+			schemas[t.String()] = payload
+		}
+	}
+
+	return schemas
+}
@@ -0,0 +1,38 @@
+package games
+
+var (
+	_ RankedEvent = &rankedEvent{}
+)
+
+// RankedEvent implemented by Winners events of games which can order all players by result,
+// used by payout schemes which pay not only to winners (top N).
+type RankedEvent interface {
+	// GetRanking return groups of players from best to worst, players of one group share place.
+	GetRanking() [][]Player
+}
+
+type rankedEvent struct {
+	GameEvent
+	ranking [][]Player
+}
+
+func (e *rankedEvent) GetRanking() [][]Player {
+	return e.ranking
+}
+
+// WithRanking attach ranking of players to the event.
+func WithRanking(event GameEvent, ranking [][]Player) GameEvent {
+	return &rankedEvent{
+		GameEvent: event,
+		ranking:   ranking,
+	}
+}
+
+// RankingOf return ranking carried by the event, nil if game not rank players.
+func RankingOf(event GameEvent) [][]Player {
+	if ranked, ok := event.(RankedEvent); ok {
+		return ranked.GetRanking()
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package games
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+)
+
+var (
+	ErrUnknownGameType = errors.New("unknown game type")
+	ErrNotVerifiable   = errors.New("game result can not be verified")
+	ErrNotReplayable   = errors.New("game result can not be replayed")
+)
+
+// Env is dependencies which runtime pass to the games.
+type Env struct {
+	Clock  clock.Clock
+	Random random.Source
+}
+
+// Factory describe game type: how to create instance from api payload, how to validate it
+// and how to decode join action.
+type Factory interface {
+	GameType() GameType
+	Name() string
+	Limits() *Limits
+	// Schema return example of api config for creation of the game.
+	Schema() interface{}
+	// New create game from raw api payload for creator.
+	New(env *Env, creator string, payload json.RawMessage) (Game, error)
+	// JoinAction decode raw api payload to player event, nil event means game joined via AddPlayer.
+	JoinAction(playerID string, payload json.RawMessage) (PlayerEvent, error)
+}
+
+// RecordedEvent is public event of the game restored from history.
+type RecordedEvent struct {
+	Type      GameEventType
+	Timestamp time.Time
+	Msg       string
+	MD        GameMD
+	// Players ids of the event, empty for events recorded before players were stored
+	Players []string
+}
+
+// Verifier implemented by factories of provably fair games.
+type Verifier interface {
+	// Verify recompute result of the game from recorded events.
+	Verify(events []*RecordedEvent) (GameMD, error)
+}
+
+// Replayer implemented by factories of deterministic games.
+type Replayer interface {
+	// Replay recompute winners from inputs stored in recorded events (choices, numbers, moves).
+	Replay(events []*RecordedEvent) ([]string, error)
+}
+
+// HouseBankedFactory implemented by factories of HouseBanked games, Winners event of such game recorded without players
+// when nobody won against house.
+type HouseBankedFactory interface {
+	// HouseBanked report games of the factory are played against house.
+	HouseBanked() bool
+}
+
+// LastEvent return last recorded event of one of types, nil if not found.
+func LastEvent(events []*RecordedEvent, types ...GameEventType) *RecordedEvent {
+	for i := len(events) - 1; i >= 0; i-- {
+		for _, t := range types {
+			if events[i].Type == t {
+				return events[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// QuickPlay implemented by factories of games which matchmaking can create, game should not need join actions.
+type QuickPlay interface {
+	// QuickPlay create game with default settings for creator, players validated by Limits.
+	QuickPlay(env *Env, creator string, cost float64, players uint8) (Game, error)
+}
+
+// Hosted implemented by factories of games which house can host on schedule.
+type Hosted interface {
+	// Host create game from raw api payload, house is creator of the game but not take seat and not stake.
+	Host(env *Env, house string, payload json.RawMessage) (Game, error)
+}
+
+type Limits struct {
+	MinCost     float64
+	MaxCost     float64
+	MinPlayers  uint8
+	MaxPlayers  uint8
+	MinDuration time.Duration
+	MaxDuration time.Duration
+}
+
+// DefaultLimits return limits from global config.
+func DefaultLimits() *Limits {
+	return &Limits{
+		MinCost:     config.MIN_GAME_COST,
+		MaxCost:     config.MAX_GAME_COST,
+		MinPlayers:  config.MIN_PLAYERS,
+		MaxPlayers:  config.MAX_PLAYERS,
+		MinDuration: config.MIN_GAME_DURATION,
+		MaxDuration: config.MAX_GAME_DURATION,
+	}
+}
+
+func (l *Limits) Validate(cost float64, players uint8, duration time.Duration) error {
+	if cost < l.MinCost || cost > l.MaxCost {
+		return fmt.Errorf("game cost from %.2f to %.2f", l.MinCost, l.MaxCost)
+	}
+
+	if players < l.MinPlayers || players > l.MaxPlayers {
+		return fmt.Errorf("game players from %d to %d", l.MinPlayers, l.MaxPlayers)
+	}
+
+	if duration < l.MinDuration || duration > l.MaxDuration {
+		return fmt.Errorf("game duration value from %s to %s", l.MinDuration.String(), l.MaxDuration.String())
+	}
+
+	return nil
+}
+
+func (l *Limits) JSON() map[string]interface{} {
+	return map[string]interface{}{
+		"min_cost":     l.MinCost,
+		"max_cost":     l.MaxCost,
+		"min_players":  l.MinPlayers,
+		"max_players":  l.MaxPlayers,
+		"min_duration": int(l.MinDuration.Seconds()),
+		"max_duration": int(l.MaxDuration.Seconds()),
+	}
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = make(map[GameType]Factory)
+)
+
+// Register make game type available for api, should be called from init of game package.
+func Register(f Factory) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, exists := registry[f.GameType()]; exists {
+		panic(fmt.Sprintf("games: register called twice for game type %d", f.GameType()))
+	}
+
+	registry[f.GameType()] = f
+}
+
+func GetFactory(gameType GameType) (Factory, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	f, exists := registry[gameType]
+	if !exists {
+		return nil, ErrUnknownGameType
+	}
+
+	return f, nil
+}
+
+// Factories return all registered game types ordered by type.
+func Factories() []Factory {
+	registryMutex.RLock()
+	list := make([]Factory, 0, len(registry))
+	for _, f := range registry {
+		list = append(list, f)
+	}
+	registryMutex.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GameType() < list[j].GameType()
+	})
+
+	return list
+}
+
+// ParseGameType convert api representation of game type to registered GameType.
+func ParseGameType(s string) (GameType, error) {
+	value, err := strconv.ParseInt(s, 10, 8)
+	if err != nil {
+		return 0, ErrUnknownGameType
+	}
+
+	gameType := GameType(value)
+	if _, errFactory := GetFactory(gameType); errFactory != nil {
+		return 0, errFactory
+	}
+
+	return gameType, nil
+}
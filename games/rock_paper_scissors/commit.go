@@ -0,0 +1,30 @@
+package rock_paper_scissors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidCommit = errors.New("commit should be hex of sha256")
+	ErrInvalidReveal = errors.New("reveal not match commit")
+	ErrNotRevealTime = errors.New("reveal allowed only after lobby closed")
+)
+
+// CommitChoice return hex of sha256 from decimal choice concatenated with salt.
+func CommitChoice(choice Choice, salt string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d%s", choice, salt)))
+	return hex.EncodeToString(sum[:])
+}
+
+func validCommit(commit string) bool {
+	raw, err := hex.DecodeString(commit)
+	return err == nil && len(raw) == sha256.Size
+}
+
+func checkReveal(commit string, reveal *PlayerRevealEvent) bool {
+	return strings.EqualFold(commit, CommitChoice(reveal.Choice, reveal.Salt))
+}
@@ -2,4 +2,11 @@ package rock_paper_scissors
 
 type PlayerChoiceEvent struct {
 	Choice Choice `json:"choice"`
+	// Commit is hex of sha256(choice || salt), used instead of choice in commit-reveal mode
+	Commit string `json:"commit,omitempty"`
+}
+
+type PlayerRevealEvent struct {
+	Choice Choice `json:"choice"`
+	Salt   string `json:"salt"`
 }
@@ -0,0 +1,151 @@
+package rock_paper_scissors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory  = &factory{}
+	_ games.Restorer = &factory{}
+	_ games.Payloads = &factory{}
+
+	ErrSeriesPlayers      = errors.New("series mode available only for 2 players")
+	ErrSeriesCommitReveal = errors.New("series mode can not be combined with commit-reveal")
+)
+
+func init() {
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+		rules:  ClassicRules,
+	})
+	games.Register(&factory{
+		limits: games.DefaultLimits(),
+		rules:  LizardSpockRules,
+	})
+}
+
+type RockPaperScissorsJoinCfg struct {
+	Choice Choice `json:"choice"`
+	Commit string `json:"commit"`
+}
+
+type RockPaperScissorsConfig struct {
+	Choice          Choice  `json:"choice"`
+	Commit          string  `json:"commit"`
+	Cost            float64 `json:"cost"`
+	NumberOfPlayers uint8   `json:"number_of_players"`
+	Duration        int     `json:"duration"`
+	CommitReveal    bool    `json:"commit_reveal"`
+	RevealDuration  int     `json:"reveal_duration"`
+	Rounds          uint8   `json:"rounds"`
+	RoundDuration   int     `json:"round_duration"`
+}
+
+type factory struct {
+	limits *games.Limits
+	rules  *Rules
+}
+
+func (f *factory) GameType() games.GameType {
+	return f.rules.GameType()
+}
+
+func (f *factory) Name() string {
+	return f.rules.Name()
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &RockPaperScissorsConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(RockPaperScissorsConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, apiCfg.NumberOfPlayers, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	revealDuration := time.Duration(apiCfg.RevealDuration) * time.Second
+
+	if apiCfg.CommitReveal && (revealDuration < config.MIN_REVEAL_DURATION || revealDuration > config.MAX_REVEAL_DURATION) {
+		return nil, fmt.Errorf("game reveal duration value from %s to %s", config.MIN_REVEAL_DURATION.String(), config.MAX_REVEAL_DURATION.String())
+	}
+
+	roundDuration := time.Duration(apiCfg.RoundDuration) * time.Second
+
+	if apiCfg.Rounds > 1 {
+		if apiCfg.Rounds > config.MAX_ROUNDS {
+			return nil, fmt.Errorf("game rounds from 1 to %d", config.MAX_ROUNDS)
+		}
+
+		if apiCfg.NumberOfPlayers != 2 {
+			return nil, ErrSeriesPlayers
+		}
+
+		if apiCfg.CommitReveal {
+			return nil, ErrSeriesCommitReveal
+		}
+
+		if roundDuration < config.MIN_ROUND_DURATION || roundDuration > config.MAX_ROUND_DURATION {
+			return nil, fmt.Errorf("game round duration value from %s to %s", config.MIN_ROUND_DURATION.String(), config.MAX_ROUND_DURATION.String())
+		}
+	}
+
+	event, errEvent := newChoiceEvent(apiCfg.Choice, apiCfg.Commit, creator)
+	if errEvent != nil {
+		return nil, errEvent
+	}
+
+	return New(&games.RockPaperConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: apiCfg.NumberOfPlayers,
+		Duration:        gameDuration,
+		CommitReveal:    apiCfg.CommitReveal,
+		RevealDuration:  revealDuration,
+		Rounds:          apiCfg.Rounds,
+		RoundDuration:   roundDuration,
+	}, f.rules, creator, event, env.Clock)
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return Restore(snapshot, f.rules, env.Clock)
+}
+
+func (f *factory) JoinAction(playerID string, payload json.RawMessage) (games.PlayerEvent, error) {
+	joinCfg := new(RockPaperScissorsJoinCfg)
+	errCfg := json.Unmarshal(payload, joinCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	return newChoiceEvent(joinCfg.Choice, joinCfg.Commit, playerID)
+}
+
+func newChoiceEvent(choice Choice, commit string, playerID string) (games.PlayerEvent, error) {
+	payload, err := json.Marshal(&PlayerChoiceEvent{
+		Choice: choice,
+		Commit: commit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return games.NewPlayerEvent(playerID, payload), nil
+}
@@ -9,61 +9,42 @@ import (
 	"time"
 
 	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
 	"github.com/google/uuid"
 )
 
 var (
-	_ games.Game = &spsGame{}
+	_ games.Game         = &spsGame{}
+	_ games.Configurable = &spsGame{}
 
 	ErrIncorrectGame = errors.New("incorrect game")
 )
 
-type Choice uint8
-
-const (
-	Rock Choice = iota
-	Paper
-	Scissors
-)
-
-func findWinners(players []*playerWithAction) []games.Player {
-	if len(players) == 1 {
-		return []games.Player{&games.BasePlayer{
-			Id: players[0].player.GetId(),
-		}}
-	}
-
-	choiceCounts := make(map[Choice]int)
+// findWinners return players with choices not beaten by any other choice on the table,
+// all players if every choice is beaten (draw).
+func findWinners(rules *Rules, players []*playerWithAction) []games.Player {
+	present := make(map[Choice]bool)
 	for _, player := range players {
-		choiceCounts[player.choice]++
+		present[player.choice] = true
 	}
 
-	// If all choices are the same or there is one of each, it's a draw
-	if len(choiceCounts) == 1 || len(choiceCounts) == 3 {
-		allPlayers := make([]games.Player, len(players))
-		for i, pp := range players {
-			allPlayers[i] = &games.BasePlayer{
-				Id: pp.player.GetId(),
+	unbeaten := make(map[Choice]bool)
+	for choice := range present {
+		beaten := false
+		for other := range present {
+			if rules.Beats(other, choice) {
+				beaten = true
+				break
 			}
 		}
-		return allPlayers // Indicates a draw
-	}
-
-	// Identify the dominant choice (the one that wins against all others)
-	var dominantChoice Choice
-	for choice := range choiceCounts {
-		if (choice == Rock && choiceCounts[Scissors] > 0 && choiceCounts[Paper] == 0) ||
-			(choice == Scissors && choiceCounts[Paper] > 0 && choiceCounts[Rock] == 0) ||
-			(choice == Paper && choiceCounts[Rock] > 0 && choiceCounts[Scissors] == 0) {
-			dominantChoice = choice
-			break
+		if !beaten {
+			unbeaten[choice] = true
 		}
 	}
 
-	// Find players with the dominant choice
 	winners := []games.Player{}
 	for _, player := range players {
-		if player.choice == dominantChoice {
+		if len(unbeaten) == 0 || unbeaten[player.choice] {
 			winners = append(winners, &games.BasePlayer{
 				Id: player.player.GetId(),
 			})
@@ -76,11 +57,16 @@ func findWinners(players []*playerWithAction) []games.Player {
 type playerWithAction struct {
 	player games.Player
 	choice Choice
+
+	commit   string
+	salt     string
+	revealed bool
 }
 
 type spsGame struct {
 	id      string
 	cfg     *games.RockPaperConfig
+	rules   *Rules
 	players map[string]*playerWithAction
 
 	updates chan games.GameEvent
@@ -88,28 +74,67 @@ type spsGame struct {
 	createdTime time.Time
 	mutex       sync.Mutex
 
-	ticker *time.Ticker
+	clock clock.Clock
+	timer clock.Timer
+	// deadline of the current timer (lobby, reveal or round), kept in snapshot
+	deadline time.Time
+	restored bool
 
 	gameCtx  context.Context
 	gameStop context.CancelFunc
 	finished bool
 	creator  string
+
+	// lobbyClosed set when players can not join or left anymore
+	lobbyClosed bool
+	// revealing set when reveal phase started in commit-reveal mode
+	revealing bool
+	// allRevealed closed when every player revealed choice in commit-reveal mode
+	allRevealed chan struct{}
+
+	// full closed when all seats taken in series mode
+	full         chan struct{}
+	round        uint8
+	inRound      bool
+	roundChoices map[string]Choice
+	roundDone    chan struct{}
+	rounds       []*roundResult
+	score        map[string]int
 }
 
 func (g *spsGame) AddPlayer(player games.Player) error {
 	return games.ErrInvalidAction
 }
 
-func getActon(event games.PlayerEvent) (*PlayerChoiceEvent, error) {
+func getActon(event games.PlayerEvent, rules *Rules, commitReveal bool) (*PlayerChoiceEvent, error) {
 	choiceEvent := &PlayerChoiceEvent{}
 	errEvent := json.Unmarshal(event.GetRawData(), choiceEvent)
 	if errEvent != nil {
 		return nil, games.ErrInvalidAction
 	}
 
+	if commitReveal {
+		if !validCommit(choiceEvent.Commit) {
+			return nil, ErrInvalidCommit
+		}
+		return choiceEvent, nil
+	}
+
+	if !rules.Valid(choiceEvent.Choice) {
+		return nil, games.ErrInvalidAction
+	}
+
 	return choiceEvent, nil
 }
 
+func newPlayerWithAction(player games.Player, choiceEvent *PlayerChoiceEvent) *playerWithAction {
+	return &playerWithAction{
+		player: player,
+		choice: choiceEvent.Choice,
+		commit: choiceEvent.Commit,
+	}
+}
+
 func (g *spsGame) AddPlayerWithAction(player games.Player, event games.PlayerEvent) error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
@@ -122,26 +147,41 @@ func (g *spsGame) AddPlayerWithAction(player games.Player, event games.PlayerEve
 		return games.ErrGameFinished
 	}
 
+	if g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
 	if _, ok := g.players[player.GetId()]; !ok {
 		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
 			return games.ErrMaxPlayer
 		}
 
-		choiceEvent, errEvent := getActon(event)
+		choiceEvent, errEvent := getActon(event, g.rules, g.cfg.CommitReveal)
 		if errEvent != nil {
 			return errEvent
 		}
 
-		g.players[player.GetId()] = &playerWithAction{
-			player: player,
-			choice: choiceEvent.Choice,
+		g.players[player.GetId()] = newPlayerWithAction(player, choiceEvent)
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", player.GetId()), true, []games.Player{player}, nil), g.snapshot())
+
+		if g.isSeries() && len(g.players) == int(g.cfg.NumberOfPlayers) {
+			g.closeLobby()
 		}
-		g.updates <- games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", player.GetId()), true, []games.Player{player}, nil)
 	}
 
 	return nil
 }
 
+// closeLobby close full only once, should be called under mutex.
+func (g *spsGame) closeLobby() {
+	if g.lobbyClosed {
+		return
+	}
+
+	g.lobbyClosed = true
+	close(g.full)
+}
+
 func (g *spsGame) GetID() string {
 	return g.id
 }
@@ -151,7 +191,7 @@ func (g *spsGame) GetCost() float64 {
 }
 
 func (g *spsGame) GameType() games.GameType {
-	return games.RockPaperScissors
+	return g.rules.GameType()
 }
 
 func (g *spsGame) GetDuration() time.Duration {
@@ -166,13 +206,17 @@ func (g *spsGame) RemovePlayer(pp games.Player) error {
 		return games.ErrGameFinished
 	}
 
+	if g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
 	if g.creator == pp.GetId() {
 		return games.ErrCreatorCantLeft
 	}
 
 	if _, ok := g.players[pp.GetId()]; ok {
 		delete(g.players, pp.GetId())
-		g.updates <- games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil)
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
 	}
 
 	return nil
@@ -181,8 +225,8 @@ func (g *spsGame) RemovePlayer(pp games.Player) error {
 func (g *spsGame) Start(ctx context.Context) error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
-	if g.ticker == nil {
-		g.ticker = time.NewTicker(g.cfg.Duration)
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
 		g.gameCtx, g.gameStop = context.WithCancel(ctx)
 		go g.loop()
 	}
@@ -196,37 +240,93 @@ func (g *spsGame) loop() {
 		g.gameStop()
 	}()
 
-	g.updates <- games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(true), nil)
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), nil), g.snapshot())
+	}
+	// game restored after lobby continue phase with timer of the phase
+	lobby := !g.revealing && g.round == 0
+	g.mutex.Unlock()
+
+	var full chan struct{}
+	if g.isSeries() {
+		full = g.full
+	}
+
+	if lobby && !g.wait(full) {
+		return
+	}
+
+	if g.cfg.CommitReveal && !g.revealPhase() {
+		return
+	}
+
+	if g.isSeries() && !g.seriesPhase() {
+		return
+	}
+
+	g.finish()
+}
 
+// wait block until current timer fired or done closed, return false if game was aborted.
+func (g *spsGame) wait(done <-chan struct{}) bool {
 	select {
 	case <-g.gameCtx.Done():
 		g.mutex.Lock()
 		g.finished = true
-		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(true), nil)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
 		g.mutex.Unlock()
-		return
-	case <-g.ticker.C:
+		return false
+	case <-g.timer.C():
+		return true
+	case <-done:
 		g.mutex.Lock()
+		g.timer.Stop()
+		g.mutex.Unlock()
+		return true
+	}
+}
 
-		g.finished = true
-		winners, md, err := g.GetWinners()
-		if err != nil {
-			g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
-			g.mutex.Unlock()
-			return
-		}
+func (g *spsGame) revealPhase() bool {
+	g.mutex.Lock()
+	g.lobbyClosed = true
+	if len(g.players) < 2 {
+		g.mutex.Unlock()
+		return true
+	}
 
-		if (len(g.players) == 1 && len(winners) == 1 && winners[0].GetId() == g.creator) || (len(winners) == len(g.players)) {
-			g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
-			g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
-			g.mutex.Unlock()
-			return
-		}
+	if !g.revealing {
+		g.revealing = true
+		g.deadline = g.clock.Now().Add(g.cfg.RevealDuration)
+		g.timer = g.clock.NewTimer(g.cfg.RevealDuration)
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, "lobby closed, reveal choices", true, g.getPlayers(false), games.NewPayload(&UpdatePayload{
+			RevealDuration: int(g.cfg.RevealDuration.Seconds()),
+		})), g.snapshot())
+	}
+	g.mutex.Unlock()
+
+	return g.wait(g.allRevealed)
+}
+
+func (g *spsGame) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
 
-		g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	if (len(g.players) == 1 && len(winners) == 1 && winners[0].GetId() == g.creator) || len(winners) == 0 || (len(winners) == len(g.players)) {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "no winners, money back", true, g.getPlayers(false), md)
 		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
-		g.mutex.Unlock()
+		return
 	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
 }
 
 func (g *spsGame) getPlayers(withMutex bool) []games.Player {
@@ -248,54 +348,71 @@ func (g *spsGame) getPlayers(withMutex bool) []games.Player {
 func (g *spsGame) Abort() error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
-	if g.ticker == nil {
+	if g.timer == nil {
 		return nil
 	}
 
-	g.ticker.Stop()
+	g.timer.Stop()
 	g.gameStop()
 	return nil
 }
 
 type playerChoice struct {
-	Choice   Choice `json:"choice"`
-	PlayerID string `json:"player_id"`
+	Choice   *Choice `json:"choice"`
+	PlayerID string  `json:"player_id"`
+	Commit   string  `json:"commit,omitempty"`
+	Salt     string  `json:"salt,omitempty"`
 }
 
 func (g *spsGame) GetWinners() ([]games.Player, games.GameMD, error) {
-	players := make([]*playerWithAction, len(g.players))
+	players := make([]*playerWithAction, 0, len(g.players))
+	// in commit-reveal mode players without reveal forfeit
+	participants := make([]*playerWithAction, 0, len(g.players))
 
-	i := 0
 	for _, p := range g.players {
-		players[i] = p
-		i += 1
+		players = append(players, p)
+		if !g.cfg.CommitReveal || p.revealed {
+			participants = append(participants, p)
+		}
 	}
 
 	if len(players) < 1 {
 		return nil, nil, ErrIncorrectGame
 	}
 
+	if g.isSeries() && len(g.rounds) > 0 {
+		winners, md := g.seriesWinners()
+		return winners, md, nil
+	}
+
 	mdChoices := make([]*playerChoice, len(players))
 
 	for index, pp := range players {
 		mdChoices[index] = &playerChoice{
-			Choice:   pp.choice,
 			PlayerID: pp.player.GetId(),
+			Commit:   pp.commit,
+			Salt:     pp.salt,
+		}
+		if !g.cfg.CommitReveal || pp.revealed {
+			choice := pp.choice
+			mdChoices[index].Choice = &choice
 		}
 	}
 
-	winners := findWinners(players)
+	var winners []games.Player
+	if len(participants) > 0 {
+		winners = findWinners(g.rules, participants)
+	}
+
 	arrWinners := make([]string, len(winners))
 	for index, player := range winners {
 		arrWinners[index] = player.GetId()
 	}
 
-	md := map[string]interface{}{
-		"players": mdChoices,
-		"winners": arrWinners,
-	}
-
-	return winners, md, nil
+	return winners, games.NewPayload(&ResultPayload{
+		Players: mdChoices,
+		Winners: arrWinners,
+	}), nil
 }
 
 func (g *spsGame) Updates() <-chan games.GameEvent {
@@ -303,7 +420,53 @@ func (g *spsGame) Updates() <-chan games.GameEvent {
 }
 
 func (g *spsGame) SendUserEvent(event games.PlayerEvent) error {
-	return games.ErrInvalidAction
+	if g.isSeries() {
+		return g.sendRoundChoice(event)
+	}
+
+	if !g.cfg.CommitReveal {
+		return games.ErrInvalidAction
+	}
+
+	revealEvent := &PlayerRevealEvent{}
+	errEvent := json.Unmarshal(event.GetRawData(), revealEvent)
+	if errEvent != nil || !g.rules.Valid(revealEvent.Choice) {
+		return games.ErrInvalidAction
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if !g.lobbyClosed {
+		return ErrNotRevealTime
+	}
+
+	pp, ok := g.players[event.GetPlayerId()]
+	if !ok || pp.revealed {
+		return games.ErrInvalidAction
+	}
+
+	if !checkReveal(pp.commit, revealEvent) {
+		return ErrInvalidReveal
+	}
+
+	pp.choice = revealEvent.Choice
+	pp.salt = revealEvent.Salt
+	pp.revealed = true
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("player: %s revealed choice", pp.player.GetId()), true, []games.Player{pp.player}, nil), g.snapshot())
+
+	for _, p := range g.players {
+		if !p.revealed {
+			return nil
+		}
+	}
+	close(g.allRevealed)
+
+	return nil
 }
 
 func (g *spsGame) GetCreator() string {
@@ -314,8 +477,18 @@ func (g *spsGame) GetMaxPlayers() uint8 {
 	return g.cfg.NumberOfPlayers
 }
 
-func New(cfg *games.RockPaperConfig, creator string, action games.PlayerEvent) (games.Game, error) {
-	choiceEvent, errEvent := getActon(action)
+func (g *spsGame) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"choices":         g.rules.Choices(),
+		"commit_reveal":   g.cfg.CommitReveal,
+		"reveal_duration": int(g.cfg.RevealDuration.Seconds()),
+		"rounds":          g.cfg.Rounds,
+		"round_duration":  int(g.cfg.RoundDuration.Seconds()),
+	}
+}
+
+func New(cfg *games.RockPaperConfig, rules *Rules, creator string, action games.PlayerEvent, clk clock.Clock) (games.Game, error) {
+	choiceEvent, errEvent := getActon(action, rules, cfg.CommitReveal)
 	if errEvent != nil {
 		return nil, errEvent
 	}
@@ -323,16 +496,19 @@ func New(cfg *games.RockPaperConfig, creator string, action games.PlayerEvent) (
 	return &spsGame{
 		id:          uuid.New().String(),
 		cfg:         cfg,
+		rules:       rules,
 		updates:     make(chan games.GameEvent),
-		createdTime: time.Now(),
+		allRevealed: make(chan struct{}),
+		full:        make(chan struct{}),
+		score:       make(map[string]int),
+		createdTime: clk.Now(),
+		clock:       clk,
+		deadline:    clk.Now().Add(cfg.Duration),
 		creator:     creator,
 		players: map[string]*playerWithAction{
-			creator: {
-				player: &games.BasePlayer{
-					Id: creator,
-				},
-				choice: choiceEvent.Choice,
-			},
+			creator: newPlayerWithAction(&games.BasePlayer{
+				Id: creator,
+			}, choiceEvent),
 		},
 	}, nil
 }
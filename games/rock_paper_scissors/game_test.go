@@ -0,0 +1,146 @@
+package rock_paper_scissors
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	gameDuration   = 30 * time.Second
+	revealDuration = 10 * time.Second
+)
+
+func choiceEvent(t *testing.T, playerID string, event interface{}) games.PlayerEvent {
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	return games.NewPlayerEvent(playerID, payload)
+}
+
+func newTestGame(t *testing.T, cfg *games.RockPaperConfig, creatorChoice *PlayerChoiceEvent) (*spsGame, *clock.Manual, <-chan games.GameEvent) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	g, err := New(cfg, ClassicRules, "creator", choiceEvent(t, "creator", creatorChoice), clk)
+	require.NoError(t, err)
+
+	// updates not buffered, game block on send until event read
+	events := make(chan games.GameEvent, 16)
+	go func() {
+		defer close(events)
+		for event := range g.Updates() {
+			events <- event
+		}
+	}()
+
+	return g.(*spsGame), clk, events
+}
+
+func next(t *testing.T, events <-chan games.GameEvent) games.GameEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "updates closed")
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event from game")
+		return nil
+	}
+}
+
+func requireClosed(t *testing.T, events <-chan games.GameEvent) {
+	select {
+	case event, ok := <-events:
+		require.False(t, ok, "unexpected event %v", event)
+	case <-time.After(time.Second):
+		require.FailNow(t, "updates not closed")
+	}
+}
+
+func classicConfig() *games.RockPaperConfig {
+	return &games.RockPaperConfig{
+		Cost:            1,
+		NumberOfPlayers: 2,
+		Duration:        gameDuration,
+		Rounds:          1,
+	}
+}
+
+func TestGameWinnersAfterDuration(t *testing.T) {
+	g, clk, events := newTestGame(t, classicConfig(), &PlayerChoiceEvent{Choice: Rock})
+	require.NoError(t, g.AddPlayerWithAction(&games.BasePlayer{Id: "player"}, choiceEvent(t, "player", &PlayerChoiceEvent{Choice: Scissors})))
+	require.Equal(t, games.PlayerJoin, next(t, events).GetEventType())
+
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	clk.Advance(gameDuration)
+	winners := next(t, events)
+	require.Equal(t, games.Winners, winners.GetEventType())
+	require.Len(t, winners.Players(), 1)
+	require.Equal(t, "creator", winners.Players()[0].GetId())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+}
+
+func TestGameDrawNoWinners(t *testing.T) {
+	g, clk, events := newTestGame(t, classicConfig(), &PlayerChoiceEvent{Choice: Paper})
+	require.NoError(t, g.AddPlayerWithAction(&games.BasePlayer{Id: "player"}, choiceEvent(t, "player", &PlayerChoiceEvent{Choice: Paper})))
+	require.Equal(t, games.PlayerJoin, next(t, events).GetEventType())
+
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	clk.Advance(gameDuration)
+	require.Equal(t, games.NoWinners, next(t, events).GetEventType())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+}
+
+func TestGameCommitRevealWinners(t *testing.T) {
+	cfg := classicConfig()
+	cfg.CommitReveal = true
+	cfg.RevealDuration = revealDuration
+
+	g, clk, events := newTestGame(t, cfg, &PlayerChoiceEvent{Commit: CommitChoice(Rock, "creator-salt")})
+	require.NoError(t, g.AddPlayerWithAction(&games.BasePlayer{Id: "player"}, choiceEvent(t, "player", &PlayerChoiceEvent{Commit: CommitChoice(Paper, "player-salt")})))
+	require.Equal(t, games.PlayerJoin, next(t, events).GetEventType())
+
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	require.ErrorIs(t, g.SendUserEvent(choiceEvent(t, "creator", &PlayerRevealEvent{Choice: Rock, Salt: "creator-salt"})), ErrNotRevealTime)
+
+	clk.Advance(gameDuration)
+	require.Equal(t, games.Update, next(t, events).GetEventType())
+
+	require.ErrorIs(t, g.SendUserEvent(choiceEvent(t, "creator", &PlayerRevealEvent{Choice: Paper, Salt: "creator-salt"})), ErrInvalidReveal)
+	require.NoError(t, g.SendUserEvent(choiceEvent(t, "creator", &PlayerRevealEvent{Choice: Rock, Salt: "creator-salt"})))
+	require.Equal(t, games.Update, next(t, events).GetEventType())
+	require.NoError(t, g.SendUserEvent(choiceEvent(t, "player", &PlayerRevealEvent{Choice: Paper, Salt: "player-salt"})))
+	require.Equal(t, games.Update, next(t, events).GetEventType())
+
+	// all revealed, game not wait reveal timer
+	winners := next(t, events)
+	require.Equal(t, games.Winners, winners.GetEventType())
+	require.Len(t, winners.Players(), 1)
+	require.Equal(t, "player", winners.Players()[0].GetId())
+	require.Equal(t, games.Finished, next(t, events).GetEventType())
+	requireClosed(t, events)
+}
+
+func TestGameAbort(t *testing.T) {
+	g, clk, events := newTestGame(t, classicConfig(), &PlayerChoiceEvent{Choice: Rock})
+	require.NoError(t, g.Start(context.Background()))
+	require.Equal(t, games.Start, next(t, events).GetEventType())
+
+	require.NoError(t, g.Abort())
+	require.Equal(t, games.Abort, next(t, events).GetEventType())
+	requireClosed(t, events)
+
+	clk.Advance(gameDuration)
+	require.Equal(t, 0, clk.Timers())
+}
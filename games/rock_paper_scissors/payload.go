@@ -0,0 +1,37 @@
+package rock_paper_scissors
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// UpdatePayload published when lobby closed in commit-reveal mode (reveal duration),
+// when series round started (round, round duration) and when series round played (result, score).
+type UpdatePayload struct {
+	RevealDuration int            `json:"reveal_duration,omitempty"`
+	Round          uint8          `json:"round,omitempty"`
+	RoundDuration  int            `json:"round_duration,omitempty"`
+	Result         *roundResult   `json:"result,omitempty"`
+	Score          map[string]int `json:"score,omitempty"`
+	Rounds         uint8          `json:"rounds,omitempty"`
+	ToWin          int            `json:"to_win,omitempty"`
+	Decided        bool           `json:"decided,omitempty"`
+}
+
+// ResultPayload published with Winners and NoWinners events, series games publish rounds and score instead of players.
+type ResultPayload struct {
+	Players []*playerChoice `json:"players,omitempty"`
+	Rounds  []*roundResult  `json:"rounds,omitempty"`
+	Score   map[string]int  `json:"score,omitempty"`
+	Winners []string        `json:"winners"`
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Update:
+		return &UpdatePayload{}
+	case games.Winners, games.NoWinners:
+		return &ResultPayload{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package rock_paper_scissors
+
+import (
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ games.Replayer = &factory{}
+)
+
+// Replay recompute winners from recorded choices, in commit-reveal mode choice should match commit.
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if resultEvent == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	result := &ResultPayload{}
+	errDecode := resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+
+	if len(result.Rounds) > 0 {
+		return f.replaySeries(result.Rounds), nil
+	}
+
+	participants := make([]*playerWithAction, 0, len(result.Players))
+	for _, pc := range result.Players {
+		if pc.Choice == nil {
+			continue
+		}
+
+		if pc.Commit != "" && !checkReveal(pc.Commit, &PlayerRevealEvent{Choice: *pc.Choice, Salt: pc.Salt}) {
+			continue
+		}
+
+		participants = append(participants, &playerWithAction{
+			player: &games.BasePlayer{Id: pc.PlayerID},
+			choice: *pc.Choice,
+		})
+	}
+
+	if len(participants) == 0 {
+		return nil, nil
+	}
+
+	winners := findWinners(f.rules, participants)
+	// alone player or everybody win - money back
+	if len(result.Players) == 1 || len(winners) == len(result.Players) {
+		return nil, nil
+	}
+
+	ids := make([]string, len(winners))
+	for i, w := range winners {
+		ids[i] = w.GetId()
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// replaySeries replay every round, player with most round wins is winner, tie of all players is money back.
+func (f *factory) replaySeries(rounds []*roundResult) []string {
+	score := make(map[string]int)
+	for _, round := range rounds {
+		participants := make([]*playerWithAction, 0, len(round.Choices))
+		for _, pc := range round.Choices {
+			if _, ok := score[pc.PlayerID]; !ok {
+				score[pc.PlayerID] = 0
+			}
+			if pc.Choice != nil {
+				participants = append(participants, &playerWithAction{
+					player: &games.BasePlayer{Id: pc.PlayerID},
+					choice: *pc.Choice,
+				})
+			}
+		}
+
+		if len(participants) == 0 {
+			continue
+		}
+
+		if winners := findWinners(f.rules, participants); len(winners) == 1 {
+			score[winners[0].GetId()] += 1
+		}
+	}
+
+	ids := make([]string, 0, len(score))
+	for id := range score {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var winners []string
+	best := -1
+	for _, id := range ids {
+		if score[id] > best {
+			best = score[id]
+			winners = []string{id}
+		} else if score[id] == best {
+			winners = append(winners, id)
+		}
+	}
+
+	if len(winners) == len(ids) {
+		return nil
+	}
+
+	return winners
+}
@@ -0,0 +1,88 @@
+package rock_paper_scissors
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+type Choice uint8
+
+const (
+	Rock Choice = iota
+	Paper
+	Scissors
+	Lizard
+	Spock
+)
+
+// Rules describe game variant: available choices and which choice beats which.
+type Rules struct {
+	gameType games.GameType
+	name     string
+	choices  []string
+	beats    map[Choice]map[Choice]bool
+}
+
+var (
+	// ClassicRules is rock-paper-scissors.
+	ClassicRules = NewCyclicRules(games.RockPaperScissors, "rock_paper_scissors", "rock", "paper", "scissors")
+	// LizardSpockRules is rock-paper-scissors-lizard-spock.
+	LizardSpockRules = NewRules(games.RockPaperScissorsLizardSpock, "rock_paper_scissors_lizard_spock", []string{"rock", "paper", "scissors", "lizard", "spock"}, map[Choice][]Choice{
+		Rock:     {Scissors, Lizard},
+		Paper:    {Rock, Spock},
+		Scissors: {Paper, Lizard},
+		Lizard:   {Paper, Spock},
+		Spock:    {Scissors, Rock},
+	})
+)
+
+// NewRules create variant from explicit dominance relation, choices are indexes of names.
+func NewRules(gameType games.GameType, name string, choices []string, beats map[Choice][]Choice) *Rules {
+	r := &Rules{
+		gameType: gameType,
+		name:     name,
+		choices:  choices,
+		beats:    make(map[Choice]map[Choice]bool, len(beats)),
+	}
+
+	for winner, losers := range beats {
+		r.beats[winner] = make(map[Choice]bool, len(losers))
+		for _, loser := range losers {
+			r.beats[winner][loser] = true
+		}
+	}
+
+	return r
+}
+
+// NewCyclicRules create variant for odd count of choices where every choice beats (n-1)/2 choices before it in cycle.
+func NewCyclicRules(gameType games.GameType, name string, choices ...string) *Rules {
+	n := len(choices)
+	beats := make(map[Choice][]Choice, n)
+	for i := 0; i < n; i++ {
+		for k := 1; k <= (n-1)/2; k++ {
+			beats[Choice(i)] = append(beats[Choice(i)], Choice((i-k+n)%n))
+		}
+	}
+
+	return NewRules(gameType, name, choices, beats)
+}
+
+func (r *Rules) GameType() games.GameType {
+	return r.gameType
+}
+
+func (r *Rules) Name() string {
+	return r.name
+}
+
+func (r *Rules) Choices() []string {
+	return r.choices
+}
+
+func (r *Rules) Valid(c Choice) bool {
+	return int(c) < len(r.choices)
+}
+
+func (r *Rules) Beats(a, b Choice) bool {
+	return r.beats[a][b]
+}
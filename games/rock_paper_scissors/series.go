@@ -0,0 +1,217 @@
+package rock_paper_scissors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	ErrNotRoundTime  = errors.New("round is not started")
+	ErrAlreadyChosen = errors.New("choice for this round already made")
+)
+
+type roundResult struct {
+	Round   uint8           `json:"round"`
+	Choices []*playerChoice `json:"choices"`
+	Winner  string          `json:"winner"`
+}
+
+func (g *spsGame) isSeries() bool {
+	return g.cfg.Rounds > 1
+}
+
+func (g *spsGame) winsNeeded() int {
+	return int(g.cfg.Rounds)/2 + 1
+}
+
+// seriesPhase play rounds until one of players reach needed wins or all rounds played, return false if game was aborted.
+func (g *spsGame) seriesPhase() bool {
+	g.mutex.Lock()
+	g.lobbyClosed = true
+	if len(g.players) < 2 {
+		g.mutex.Unlock()
+		return true
+	}
+
+	// first round played by choices from join, restored game already played it
+	if g.round == 0 {
+		firstRound := make(map[string]Choice, len(g.players))
+		for id, p := range g.players {
+			firstRound[id] = p.choice
+		}
+		g.playRound(firstRound)
+	}
+
+	// round restored in progress continue with timer of the round
+	for g.inRound || !g.seriesDecided() {
+		if !g.inRound {
+			g.round += 1
+			g.roundChoices = make(map[string]Choice, len(g.players))
+			g.roundDone = make(chan struct{})
+			g.inRound = true
+			g.deadline = g.clock.Now().Add(g.cfg.RoundDuration)
+			g.timer = g.clock.NewTimer(g.cfg.RoundDuration)
+			g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("round %d started", g.round), true, g.getPlayers(false), games.NewPayload(&UpdatePayload{
+				Round:         g.round,
+				RoundDuration: int(g.cfg.RoundDuration.Seconds()),
+				Score:         g.score,
+			})), g.snapshot())
+		}
+		roundDone := g.roundDone
+		g.mutex.Unlock()
+
+		if !g.wait(roundDone) {
+			return false
+		}
+
+		g.mutex.Lock()
+		g.inRound = false
+		g.playRound(g.roundChoices)
+	}
+	g.mutex.Unlock()
+
+	return true
+}
+
+// playRound resolve round, player without choice lose round, should be called under mutex.
+func (g *spsGame) playRound(choices map[string]Choice) {
+	if g.round == 0 {
+		g.round = 1
+	}
+
+	participants := make([]*playerWithAction, 0, len(choices))
+	mdChoices := make([]*playerChoice, 0, len(g.players))
+	for _, id := range g.sortedPlayerIds() {
+		mdChoice := &playerChoice{
+			PlayerID: id,
+		}
+		if choice, ok := choices[id]; ok {
+			mdChoice.Choice = &choice
+			participants = append(participants, &playerWithAction{
+				player: g.players[id].player,
+				choice: choice,
+			})
+		}
+		mdChoices = append(mdChoices, mdChoice)
+	}
+
+	result := &roundResult{
+		Round:   g.round,
+		Choices: mdChoices,
+	}
+
+	if len(participants) > 0 {
+		winners := findWinners(g.rules, participants)
+		if len(winners) == 1 {
+			result.Winner = winners[0].GetId()
+			g.score[result.Winner] += 1
+		}
+	}
+
+	g.rounds = append(g.rounds, result)
+
+	msg := fmt.Sprintf("round %d is draw", result.Round)
+	if result.Winner != "" {
+		msg = fmt.Sprintf("round %d won by player: %s", result.Round, result.Winner)
+	}
+
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, msg, true, g.getPlayers(false), games.NewPayload(&UpdatePayload{
+		Round:   result.Round,
+		Result:  result,
+		Score:   g.score,
+		Rounds:  g.cfg.Rounds,
+		ToWin:   g.winsNeeded(),
+		Decided: g.seriesDecided(),
+	})), g.snapshot())
+}
+
+func (g *spsGame) seriesDecided() bool {
+	if int(g.round) >= int(g.cfg.Rounds) {
+		return true
+	}
+
+	for _, wins := range g.score {
+		if wins >= g.winsNeeded() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *spsGame) sortedPlayerIds() []string {
+	ids := make([]string, 0, len(g.players))
+	for id := range g.players {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// seriesWinners return player with most round wins, all players on tie.
+func (g *spsGame) seriesWinners() ([]games.Player, games.GameMD) {
+	var winners []games.Player
+	best := -1
+	for _, id := range g.sortedPlayerIds() {
+		wins := g.score[id]
+		if wins > best {
+			best = wins
+			winners = []games.Player{g.players[id].player}
+		} else if wins == best {
+			winners = append(winners, g.players[id].player)
+		}
+	}
+
+	arrWinners := make([]string, len(winners))
+	for index, player := range winners {
+		arrWinners[index] = player.GetId()
+	}
+
+	return winners, games.NewPayload(&ResultPayload{
+		Rounds:  g.rounds,
+		Score:   g.score,
+		Winners: arrWinners,
+	})
+}
+
+func (g *spsGame) sendRoundChoice(event games.PlayerEvent) error {
+	choiceEvent := &PlayerChoiceEvent{}
+	errEvent := json.Unmarshal(event.GetRawData(), choiceEvent)
+	if errEvent != nil || !g.rules.Valid(choiceEvent.Choice) {
+		return games.ErrInvalidAction
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if !g.inRound {
+		return ErrNotRoundTime
+	}
+
+	pp, ok := g.players[event.GetPlayerId()]
+	if !ok {
+		return games.ErrInvalidAction
+	}
+
+	if _, chosen := g.roundChoices[event.GetPlayerId()]; chosen {
+		return ErrAlreadyChosen
+	}
+
+	g.roundChoices[event.GetPlayerId()] = choiceEvent.Choice
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("player: %s made choice for round %d", pp.player.GetId(), g.round), true, []games.Player{pp.player}, nil), g.snapshot())
+
+	if len(g.roundChoices) == len(g.players) {
+		close(g.roundDone)
+	}
+
+	return nil
+}
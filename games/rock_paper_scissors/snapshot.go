@@ -0,0 +1,138 @@
+package rock_paper_scissors
+
+import (
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+)
+
+type snapshotState struct {
+	Config *games.RockPaperConfig `json:"config"`
+	// Choices by player id, contains all players, only commit is set in commit-reveal mode
+	Choices map[string]*PlayerChoiceEvent `json:"choices"`
+	// Reveals by player id of players who revealed choice, Revealing set when reveal phase started
+	Reveals   map[string]*PlayerRevealEvent `json:"reveals,omitempty"`
+	Revealing bool                          `json:"revealing,omitempty"`
+	// Round is last started round of the series, InRound set while round wait for choices
+	Round        uint8             `json:"round,omitempty"`
+	InRound      bool              `json:"in_round,omitempty"`
+	RoundChoices map[string]Choice `json:"round_choices,omitempty"`
+	Rounds       []*roundResult    `json:"rounds,omitempty"`
+	Score        map[string]int    `json:"score,omitempty"`
+}
+
+// snapshot should be called under mutex.
+func (g *spsGame) snapshot() *games.Snapshot {
+	choices := make(map[string]*PlayerChoiceEvent, len(g.players))
+	reveals := make(map[string]*PlayerRevealEvent)
+	for id, pp := range g.players {
+		choices[id] = &PlayerChoiceEvent{
+			Choice: pp.choice,
+			Commit: pp.commit,
+		}
+		if pp.revealed {
+			reveals[id] = &PlayerRevealEvent{
+				Choice: pp.choice,
+				Salt:   pp.salt,
+			}
+		}
+	}
+
+	roundChoices := make(map[string]Choice, len(g.roundChoices))
+	for id, choice := range g.roundChoices {
+		roundChoices[id] = choice
+	}
+
+	score := make(map[string]int, len(g.score))
+	for id, wins := range g.score {
+		score[id] = wins
+	}
+
+	return games.NewSnapshot(g, g.deadline, &snapshotState{
+		Config:       g.cfg,
+		Choices:      choices,
+		Reveals:      reveals,
+		Revealing:    g.revealing,
+		Round:        g.round,
+		InRound:      g.inRound,
+		RoundChoices: roundChoices,
+		Rounds:       g.rounds,
+		Score:        score,
+	})
+}
+
+func Restore(snapshot *games.Snapshot, rules *Rules, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	g := &spsGame{
+		id:           snapshot.GameID,
+		cfg:          state.Config,
+		rules:        rules,
+		updates:      make(chan games.GameEvent),
+		allRevealed:  make(chan struct{}),
+		full:         make(chan struct{}),
+		score:        make(map[string]int, len(state.Score)),
+		createdTime:  clk.Now(),
+		clock:        clk,
+		deadline:     snapshot.Deadline,
+		restored:     true,
+		creator:      snapshot.Creator,
+		players:      make(map[string]*playerWithAction, len(state.Choices)),
+		revealing:    state.Revealing,
+		round:        state.Round,
+		inRound:      state.InRound,
+		roundChoices: make(map[string]Choice, len(state.RoundChoices)),
+		roundDone:    make(chan struct{}),
+		rounds:       state.Rounds,
+	}
+
+	for id, choice := range state.Choices {
+		g.players[id] = newPlayerWithAction(&games.BasePlayer{
+			Id: id,
+		}, choice)
+	}
+
+	for id, reveal := range state.Reveals {
+		pp, ok := g.players[id]
+		if !ok || !checkReveal(pp.commit, reveal) {
+			return nil, ErrInvalidReveal
+		}
+		pp.choice = reveal.Choice
+		pp.salt = reveal.Salt
+		pp.revealed = true
+	}
+
+	for id, choice := range state.RoundChoices {
+		if _, ok := g.players[id]; !ok || !rules.Valid(choice) {
+			return nil, games.ErrInvalidAction
+		}
+		g.roundChoices[id] = choice
+	}
+
+	for id, wins := range state.Score {
+		g.score[id] = wins
+	}
+
+	if g.isSeries() && len(g.players) == int(g.cfg.NumberOfPlayers) {
+		g.closeLobby()
+	}
+
+	if g.revealing || g.round > 0 {
+		g.lobbyClosed = true
+	}
+
+	if g.revealing && len(state.Reveals) == len(g.players) {
+		close(g.allRevealed)
+	}
+
+	if g.inRound && len(g.roundChoices) == len(g.players) {
+		close(g.roundDone)
+	}
+
+	return g, nil
+}
@@ -0,0 +1,65 @@
+package games
+
+import (
+	"encoding/json"
+	"time"
+)
+
+var (
+	_ SnapshotEvent = &snapshotEvent{}
+)
+
+// Snapshot is state of the game (players, choices, phase, deadline), allow restore game after restart.
+type Snapshot struct {
+	GameID   string
+	GameType GameType
+	Creator  string
+	// Deadline of current timer of the game (lobby, reveal, round, turn), restored game wait only remaining time
+	Deadline time.Time
+	State    json.RawMessage
+}
+
+// NewSnapshot serialize state of the game, return nil if state can not be serialized.
+func NewSnapshot(game Game, deadline time.Time, state interface{}) *Snapshot {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+
+	return &Snapshot{
+		GameID:   game.GetID(),
+		GameType: game.GameType(),
+		Creator:  game.GetCreator(),
+		Deadline: deadline,
+		State:    raw,
+	}
+}
+
+// SnapshotEvent implemented by events which carry state of the game right after the event.
+// Runtime store snapshot from such events, any other public event means game can not be restored anymore and snapshot is dropped.
+type SnapshotEvent interface {
+	GetSnapshot() *Snapshot
+}
+
+type snapshotEvent struct {
+	GameEvent
+	snapshot *Snapshot
+}
+
+func (e *snapshotEvent) GetSnapshot() *Snapshot {
+	return e.snapshot
+}
+
+// WithSnapshot attach snapshot to the event, should be called under game mutex.
+func WithSnapshot(event GameEvent, snapshot *Snapshot) GameEvent {
+	return &snapshotEvent{
+		GameEvent: event,
+		snapshot:  snapshot,
+	}
+}
+
+// Restorer implemented by factories of games which emit snapshots.
+type Restorer interface {
+	// Restore create game from snapshot, game continue without Start event.
+	Restore(env *Env, snapshot *Snapshot) (Game, error)
+}
@@ -0,0 +1,89 @@
+package tic_tac_toe
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/PxyUp/ton_games_example/games/turn_based"
+)
+
+var (
+	_ turn_based.Board = &board{}
+
+	ErrInvalidCell = errors.New("invalid cell")
+	ErrBusyCell    = errors.New("cell already taken")
+)
+
+const (
+	size = 3
+)
+
+var (
+	lines = [][size]int{
+		{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+		{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+		{0, 4, 8}, {2, 4, 6},
+	}
+	marks = []string{"X", "O"}
+)
+
+type PlayerMoveEvent struct {
+	// Cell from 0 to 8, row by row
+	Cell int `json:"cell"`
+}
+
+// board store index of player +1 in every cell, 0 is empty cell.
+type board struct {
+	cells [size * size]int
+	moves int
+}
+
+func (b *board) Apply(player int, move json.RawMessage) error {
+	moveEvent := &PlayerMoveEvent{}
+	errMove := turn_based.ReadMove(move, moveEvent)
+	if errMove != nil {
+		return errMove
+	}
+
+	if moveEvent.Cell < 0 || moveEvent.Cell >= len(b.cells) {
+		return ErrInvalidCell
+	}
+
+	if b.cells[moveEvent.Cell] != 0 {
+		return ErrBusyCell
+	}
+
+	b.cells[moveEvent.Cell] = player + 1
+	b.moves += 1
+	return nil
+}
+
+func (b *board) Winner() (int, bool) {
+	for _, line := range lines {
+		mark := b.cells[line[0]]
+		if mark != 0 && mark == b.cells[line[1]] && mark == b.cells[line[2]] {
+			return mark - 1, true
+		}
+	}
+
+	if b.moves == len(b.cells) {
+		return turn_based.NoWinner, true
+	}
+
+	return turn_based.NoWinner, false
+}
+
+// State return board as rows of "X", "O" and "" for empty cell.
+func (b *board) State() interface{} {
+	rows := make([][]string, size)
+	for i := range rows {
+		rows[i] = make([]string, size)
+		for j := range rows[i] {
+			if mark := b.cells[i*size+j]; mark != 0 {
+				rows[i][j] = marks[mark-1]
+			}
+		}
+	}
+
+	return rows
+}
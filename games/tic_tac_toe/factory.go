@@ -0,0 +1,116 @@
+package tic_tac_toe
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/games/turn_based"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ games.Factory   = &factory{}
+	_ games.Restorer  = &factory{}
+	_ games.QuickPlay = &factory{}
+	_ games.Payloads  = &factory{}
+	_ games.Replayer  = &factory{}
+)
+
+const (
+	players = 2
+)
+
+func init() {
+	limits := games.DefaultLimits()
+	limits.MinPlayers = players
+	limits.MaxPlayers = players
+
+	games.Register(&factory{
+		limits: limits,
+	})
+}
+
+type TicTacToeConfig struct {
+	Cost         float64 `json:"cost"`
+	Duration     int     `json:"duration"`
+	TurnDuration int     `json:"turn_duration"`
+}
+
+type factory struct {
+	limits *games.Limits
+}
+
+func (f *factory) GameType() games.GameType {
+	return games.TicTacToe
+}
+
+func (f *factory) Name() string {
+	return "tic_tac_toe"
+}
+
+func (f *factory) Limits() *games.Limits {
+	return f.limits
+}
+
+func (f *factory) Schema() interface{} {
+	return &TicTacToeConfig{}
+}
+
+func (f *factory) New(env *games.Env, creator string, payload json.RawMessage) (games.Game, error) {
+	apiCfg := new(TicTacToeConfig)
+	errCfg := json.Unmarshal(payload, apiCfg)
+	if errCfg != nil {
+		return nil, errCfg
+	}
+
+	gameDuration := time.Duration(apiCfg.Duration) * time.Second
+	turnDuration := time.Duration(apiCfg.TurnDuration) * time.Second
+
+	errLimits := f.limits.Validate(apiCfg.Cost, players, gameDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	if turnDuration < config.MIN_TURN_DURATION || turnDuration > config.MAX_TURN_DURATION {
+		return nil, fmt.Errorf("game turn duration value from %s to %s", config.MIN_TURN_DURATION.String(), config.MAX_TURN_DURATION.String())
+	}
+
+	return turn_based.New(games.TicTacToe, &games.TurnBasedConfig{
+		Cost:            apiCfg.Cost,
+		NumberOfPlayers: players,
+		Duration:        gameDuration,
+		TurnDuration:    turnDuration,
+	}, creator, &board{}, env.Clock), nil
+}
+
+func (f *factory) QuickPlay(env *games.Env, creator string, cost float64, _ uint8) (games.Game, error) {
+	return turn_based.New(games.TicTacToe, &games.TurnBasedConfig{
+		Cost:            cost,
+		NumberOfPlayers: players,
+		Duration:        config.QUICK_PLAY_DURATION,
+		TurnDuration:    config.QUICK_PLAY_TURN_DURATION,
+	}, creator, &board{}, env.Clock), nil
+}
+
+func (f *factory) Restore(env *games.Env, snapshot *games.Snapshot) (games.Game, error) {
+	return turn_based.Restore(snapshot, &board{}, env.Clock)
+}
+
+func (f *factory) JoinAction(_ string, _ json.RawMessage) (games.PlayerEvent, error) {
+	return nil, nil
+}
+
+func (f *factory) Payload(eventType games.GameEventType) interface{} {
+	switch eventType {
+	case games.Update, games.Winners, games.NoWinners:
+		return &turn_based.BoardPayload{}
+	default:
+		return nil
+	}
+}
+
+func (f *factory) Replay(events []*games.RecordedEvent) ([]string, error) {
+	return turn_based.Replay(events, &board{})
+}
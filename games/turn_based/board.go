@@ -0,0 +1,21 @@
+package turn_based
+
+import (
+	"encoding/json"
+)
+
+const (
+	// NoWinner returned by Board.Winner when game finished in a draw
+	NoWinner = -1
+)
+
+// Board is rules and state of turn based game, players identified by index in turn order.
+// Framework call Board only under own lock, so implementation should not be thread safe.
+type Board interface {
+	// Apply validate move of the player and apply it to the board.
+	Apply(player int, move json.RawMessage) error
+	// Winner return index of the winner or NoWinner, finished is false while game should continue.
+	Winner() (winner int, finished bool)
+	// State return public representation of the board.
+	State() interface{}
+}
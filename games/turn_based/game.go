@@ -0,0 +1,476 @@
+package turn_based
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/google/uuid"
+)
+
+var (
+	_ games.Game         = &game{}
+	_ games.Configurable = &game{}
+
+	ErrNotYourTurn  = errors.New("not your turn")
+	ErrNotPlayer    = errors.New("player not part of the game")
+	ErrNotStarted   = errors.New("game wait for players")
+	ErrInvalidBoard = errors.New("board finished with unknown winner")
+)
+
+// game is lobby until all seats taken, after that players make moves in join order.
+// Player who not made move before turn deadline forfeit and all other players win.
+type game struct {
+	id       string
+	gameType games.GameType
+	cfg      *games.TurnBasedConfig
+	board    Board
+
+	players map[string]games.Player
+	order   []games.Player
+
+	updates chan games.GameEvent
+
+	createdTime time.Time
+	mutex       sync.Mutex
+
+	clock     clock.Clock
+	timer     clock.Timer
+	turnTimer clock.Timer
+	// lobbyDeadline is deadline of the lobby timer, kept in snapshot
+	lobbyDeadline time.Time
+	restored      bool
+	// full closed when all seats taken
+	full chan struct{}
+	// lobbyClosed set with close of full, players can not join or left after it
+	lobbyClosed bool
+	// moved signal loop about applied move
+	moved chan struct{}
+
+	started  bool
+	turn     int
+	current  int
+	deadline time.Time
+	done     bool
+	// moves applied to the board, kept in snapshot to rebuild board on restore
+	moves []*move
+
+	gameCtx  context.Context
+	gameStop context.CancelFunc
+	finished bool
+	creator  string
+}
+
+func (g *game) AddPlayerWithAction(player games.Player, _ games.PlayerEvent) error {
+	return g.AddPlayer(player)
+}
+
+func (g *game) AddPlayer(p games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.creator == p.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
+	if _, ok := g.players[p.GetId()]; !ok {
+		if len(g.players) >= int(g.cfg.NumberOfPlayers) {
+			return games.ErrMaxPlayer
+		}
+
+		g.players[p.GetId()] = p
+		g.order = append(g.order, p)
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerJoin, fmt.Sprintf("player: %s join game", p.GetId()), true, []games.Player{p}, nil), g.snapshot())
+
+		if len(g.players) == int(g.cfg.NumberOfPlayers) {
+			g.closeLobby()
+		}
+	}
+
+	return nil
+}
+
+// closeLobby close full only once, should be called under mutex.
+func (g *game) closeLobby() {
+	if g.lobbyClosed {
+		return
+	}
+
+	g.lobbyClosed = true
+	close(g.full)
+}
+
+func (g *game) RemovePlayer(pp games.Player) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished {
+		return games.ErrGameFinished
+	}
+
+	if g.started || g.lobbyClosed {
+		return games.ErrGameStarted
+	}
+
+	if g.creator == pp.GetId() {
+		return games.ErrCreatorCantLeft
+	}
+
+	if _, ok := g.players[pp.GetId()]; ok {
+		delete(g.players, pp.GetId())
+		for i := range g.order {
+			if g.order[i].GetId() == pp.GetId() {
+				g.order = append(g.order[:i], g.order[i+1:]...)
+				break
+			}
+		}
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.PlayerLeft, fmt.Sprintf("player: %s left game", pp.GetId()), true, []games.Player{pp}, nil), g.snapshot())
+	}
+
+	return nil
+}
+
+// SendUserEvent apply move of the player, payload passed to the board as is.
+func (g *game) SendUserEvent(event games.PlayerEvent) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.finished || g.done {
+		return games.ErrGameFinished
+	}
+
+	if !g.started {
+		return ErrNotStarted
+	}
+
+	if _, ok := g.players[event.GetPlayerId()]; !ok {
+		return ErrNotPlayer
+	}
+
+	if g.order[g.current].GetId() != event.GetPlayerId() {
+		return ErrNotYourTurn
+	}
+
+	errMove := g.board.Apply(g.current, event.GetRawData())
+	if errMove != nil {
+		return errMove
+	}
+
+	player := g.order[g.current]
+	raw := event.GetRawData()
+	g.moves = append(g.moves, &move{
+		Player: g.current,
+		Move:   raw,
+	})
+
+	_, g.done = g.board.Winner()
+	if !g.done {
+		g.nextTurn()
+	}
+
+	g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, fmt.Sprintf("player: %s made move", player.GetId()), true, []games.Player{player}, g.boardPayload(&BoardPayload{
+		PlayerID: player.GetId(),
+		Move:     raw,
+	})), g.snapshot())
+
+	select {
+	case g.moved <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (g *game) nextTurn() {
+	g.turn += 1
+	g.current = (g.current + 1) % len(g.order)
+	g.deadline = g.clock.Now().Add(g.cfg.TurnDuration)
+}
+
+// boardPayload fill board, turn order and current turn of the payload, should be called under mutex.
+func (g *game) boardPayload(payload *BoardPayload) games.GameMD {
+	ids := make([]string, len(g.order))
+	for i := range g.order {
+		ids[i] = g.order[i].GetId()
+	}
+
+	payload.Board = g.board.State()
+	payload.Players = ids
+	if !g.done {
+		deadline := g.deadline
+		payload.Turn = g.order[g.current].GetId()
+		payload.TurnDeadline = &deadline
+	}
+
+	return games.NewPayload(payload)
+}
+
+func (g *game) GetMaxPlayers() uint8 {
+	return g.cfg.NumberOfPlayers
+}
+
+func (g *game) GameType() games.GameType {
+	return g.gameType
+}
+
+func (g *game) Start(ctx context.Context) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		g.timer = g.clock.NewTimer(g.lobbyDeadline.Sub(g.clock.Now()))
+		g.gameCtx, g.gameStop = context.WithCancel(ctx)
+		go g.loop()
+	}
+
+	return nil
+}
+
+func (g *game) getPlayers(withMutex bool) []games.Player {
+	if withMutex {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+	}
+
+	pl := make([]games.Player, len(g.order))
+	copy(pl, g.order)
+
+	return pl
+}
+
+func (g *game) abort() {
+	g.mutex.Lock()
+	g.finished = true
+	g.updates <- games.NewGameEvent(g.GetID(), games.Abort, "game is canceled", true, g.getPlayers(false), nil)
+	g.mutex.Unlock()
+}
+
+func (g *game) loop() {
+	defer func() {
+		close(g.updates)
+		g.gameStop()
+	}()
+
+	g.mutex.Lock()
+	if !g.restored {
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Start, "game is started", true, g.getPlayers(false), nil), g.snapshot())
+	}
+	started := g.started
+	g.mutex.Unlock()
+
+	// game restored after lobby continue with remaining time of the turn
+	if !started {
+		select {
+		case <-g.gameCtx.Done():
+			g.abort()
+			return
+		case <-g.timer.C():
+			g.mutex.Lock()
+			g.finished = true
+			g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "not enough players, money back", true, g.getPlayers(false), nil)
+			g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+			g.mutex.Unlock()
+			return
+		case <-g.full:
+			g.timer.Stop()
+		}
+
+		g.mutex.Lock()
+		g.started = true
+		g.deadline = g.clock.Now().Add(g.cfg.TurnDuration)
+		g.updates <- games.WithSnapshot(games.NewGameEvent(g.GetID(), games.Update, "board is ready", true, g.getPlayers(false), g.boardPayload(&BoardPayload{})), g.snapshot())
+		g.mutex.Unlock()
+	}
+
+	g.mutex.Lock()
+	armedTurn := g.turn
+	done := g.done
+	g.turnTimer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+	g.mutex.Unlock()
+
+	if done {
+		g.turnTimer.Stop()
+		g.finish()
+		return
+	}
+
+	for {
+		select {
+		case <-g.gameCtx.Done():
+			g.turnTimer.Stop()
+			g.abort()
+			return
+		case <-g.turnTimer.C():
+			if g.forfeit(armedTurn) {
+				return
+			}
+		case <-g.moved:
+			g.mutex.Lock()
+			done := g.done
+			if !done && g.turn != armedTurn {
+				g.turnTimer.Stop()
+				armedTurn = g.turn
+				g.turnTimer = g.clock.NewTimer(g.deadline.Sub(g.clock.Now()))
+			}
+			g.mutex.Unlock()
+
+			if done {
+				g.turnTimer.Stop()
+				g.finish()
+				return
+			}
+		}
+	}
+}
+
+// forfeit finish game by timeout of current player, all other players are winners.
+// Return false when move was made together with deadline, in that case moved signal is pending.
+func (g *game) forfeit(turn int) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.done || g.turn != turn {
+		return false
+	}
+
+	g.finished = true
+	g.done = true
+
+	loser := g.order[g.current]
+	var winners []games.Player
+	for _, p := range g.order {
+		if p.GetId() != loser.GetId() {
+			winners = append(winners, p)
+		}
+	}
+
+	md := g.boardPayload(&BoardPayload{
+		Forfeit: loser.GetId(),
+	})
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, fmt.Sprintf("player: %s forfeit by timeout", loser.GetId()), true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+	return true
+}
+
+func (g *game) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.finished = true
+	winners, md, err := g.GetWinners()
+	if err != nil {
+		g.updates <- games.NewGameEvent(g.GetID(), games.Error, "cant get game winners", true, g.getPlayers(false), md)
+		return
+	}
+
+	if len(winners) == 0 {
+		g.updates <- games.NewGameEvent(g.GetID(), games.NoWinners, "draw, money back", true, g.getPlayers(false), md)
+		g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+		return
+	}
+
+	g.updates <- games.NewGameEvent(g.GetID(), games.Winners, "winners reveals", true, winners, md)
+	g.updates <- games.NewGameEvent(g.GetID(), games.Finished, "game is finished", true, g.getPlayers(false), nil)
+}
+
+func (g *game) GetID() string {
+	return g.id
+}
+
+func (g *game) GetCost() float64 {
+	return g.cfg.Cost
+}
+
+func (g *game) Abort() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.timer == nil {
+		return nil
+	}
+
+	g.timer.Stop()
+	g.gameStop()
+	return nil
+}
+
+func (g *game) GetWinners() ([]games.Player, games.GameMD, error) {
+	winner, _ := g.board.Winner()
+
+	if winner == NoWinner {
+		return nil, g.boardPayload(&BoardPayload{}), nil
+	}
+
+	if winner < 0 || winner >= len(g.order) {
+		return nil, g.boardPayload(&BoardPayload{}), ErrInvalidBoard
+	}
+
+	return []games.Player{g.order[winner]}, g.boardPayload(&BoardPayload{
+		Winner: g.order[winner].GetId(),
+	}), nil
+}
+
+func (g *game) GetCreator() string {
+	return g.creator
+}
+
+func (g *game) Updates() <-chan games.GameEvent {
+	return g.updates
+}
+
+func (g *game) GetDuration() time.Duration {
+	return g.cfg.Duration
+}
+
+func (g *game) GetSettings() games.GameMD {
+	return map[string]interface{}{
+		"turn_duration": int(g.cfg.TurnDuration.Seconds()),
+	}
+}
+
+// New create turn based game of gameType, creator always make first move.
+func New(gameType games.GameType, cfg *games.TurnBasedConfig, creator string, board Board, clk clock.Clock) games.Game {
+	creatorPlayer := &games.BasePlayer{
+		Id: creator,
+	}
+
+	return &game{
+		id:            uuid.New().String(),
+		gameType:      gameType,
+		cfg:           cfg,
+		board:         board,
+		updates:       make(chan games.GameEvent),
+		full:          make(chan struct{}),
+		moved:         make(chan struct{}, 1),
+		createdTime:   clk.Now(),
+		clock:         clk,
+		creator:       creator,
+		lobbyDeadline: clk.Now().Add(cfg.Duration),
+		players: map[string]games.Player{
+			creator: creatorPlayer,
+		},
+		order: []games.Player{creatorPlayer},
+	}
+}
+
+// ReadMove helper for boards to decode move payload.
+func ReadMove(move json.RawMessage, v interface{}) error {
+	errMove := json.Unmarshal(move, v)
+	if errMove != nil {
+		return games.ErrInvalidAction
+	}
+
+	return nil
+}
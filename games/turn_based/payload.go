@@ -0,0 +1,19 @@
+package turn_based
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BoardPayload published after every move (Update) and with result, board is Board.State of the game.
+// Turn omitted when game is done, forfeit set when player lost by timeout.
+type BoardPayload struct {
+	Board        interface{}     `json:"board"`
+	Players      []string        `json:"players"`
+	Turn         string          `json:"turn,omitempty"`
+	TurnDeadline *time.Time      `json:"turn_deadline,omitempty"`
+	PlayerID     string          `json:"player_id,omitempty"`
+	Move         json.RawMessage `json:"move,omitempty"`
+	Forfeit      string          `json:"forfeit,omitempty"`
+	Winner       string          `json:"winner,omitempty"`
+}
@@ -0,0 +1,67 @@
+package turn_based
+
+import (
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+// Replay apply recorded moves to fresh board, player who forfeit by timeout lose to all other players.
+func Replay(events []*games.RecordedEvent, board Board) ([]string, error) {
+	resultEvent := games.LastEvent(events, games.Winners, games.NoWinners)
+	if resultEvent == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	result := &BoardPayload{}
+	errDecode := resultEvent.MD.Decode(result)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+
+	index := make(map[string]int, len(result.Players))
+	for i, id := range result.Players {
+		index[id] = i
+	}
+
+	for _, event := range events {
+		if event.Type != games.Update {
+			continue
+		}
+
+		move := &BoardPayload{}
+		errMove := event.MD.Decode(move)
+		if errMove != nil {
+			return nil, errMove
+		}
+
+		player, ok := index[move.PlayerID]
+		if !ok || len(move.Move) == 0 {
+			continue
+		}
+
+		errApply := board.Apply(player, move.Move)
+		if errApply != nil {
+			return nil, errApply
+		}
+	}
+
+	if result.Forfeit != "" {
+		var winners []string
+		for _, id := range result.Players {
+			if id != result.Forfeit {
+				winners = append(winners, id)
+			}
+		}
+		return winners, nil
+	}
+
+	winner, _ := board.Winner()
+	if winner == NoWinner {
+		return nil, nil
+	}
+
+	if winner < 0 || winner >= len(result.Players) {
+		return nil, ErrInvalidBoard
+	}
+
+	return []string{result.Players[winner]}, nil
+}
@@ -0,0 +1,109 @@
+package turn_based
+
+import (
+	"encoding/json"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+)
+
+type snapshotState struct {
+	Config *games.TurnBasedConfig `json:"config"`
+	// Players in turn order
+	Players []string `json:"players"`
+	// Started set after lobby, board rebuilt from Moves on restore
+	Started bool    `json:"started,omitempty"`
+	Moves   []*move `json:"moves,omitempty"`
+	Turn    int     `json:"turn,omitempty"`
+	Current int     `json:"current,omitempty"`
+}
+
+// move of the player by index in turn order.
+type move struct {
+	Player int             `json:"player"`
+	Move   json.RawMessage `json:"move"`
+}
+
+// snapshot should be called under mutex, deadline of the snapshot is lobby deadline or turn deadline after start.
+func (g *game) snapshot() *games.Snapshot {
+	players := make([]string, len(g.order))
+	for i := range g.order {
+		players[i] = g.order[i].GetId()
+	}
+
+	deadline := g.lobbyDeadline
+	if g.started {
+		deadline = g.deadline
+	}
+
+	return games.NewSnapshot(g, deadline, &snapshotState{
+		Config:  g.cfg,
+		Players: players,
+		Started: g.started,
+		Moves:   append([]*move(nil), g.moves...),
+		Turn:    g.turn,
+		Current: g.current,
+	})
+}
+
+// Restore create game from snapshot, moves of started game applied to empty board.
+func Restore(snapshot *games.Snapshot, board Board, clk clock.Clock) (games.Game, error) {
+	state := &snapshotState{}
+	errState := json.Unmarshal(snapshot.State, state)
+	if errState != nil {
+		return nil, errState
+	}
+
+	g := &game{
+		id:            snapshot.GameID,
+		gameType:      snapshot.GameType,
+		cfg:           state.Config,
+		board:         board,
+		updates:       make(chan games.GameEvent),
+		full:          make(chan struct{}),
+		moved:         make(chan struct{}, 1),
+		createdTime:   clk.Now(),
+		clock:         clk,
+		lobbyDeadline: snapshot.Deadline,
+		restored:      true,
+		creator:       snapshot.Creator,
+		players:       make(map[string]games.Player, len(state.Players)),
+		order:         make([]games.Player, len(state.Players)),
+		started:       state.Started,
+		turn:          state.Turn,
+		current:       state.Current,
+		moves:         state.Moves,
+	}
+
+	for i, id := range state.Players {
+		player := &games.BasePlayer{
+			Id: id,
+		}
+		g.players[id] = player
+		g.order[i] = player
+	}
+
+	if len(g.players) == int(g.cfg.NumberOfPlayers) {
+		g.closeLobby()
+	}
+
+	if !g.started {
+		return g, nil
+	}
+
+	if g.current < 0 || g.current >= len(g.order) {
+		return nil, ErrInvalidBoard
+	}
+
+	for _, m := range g.moves {
+		errApply := board.Apply(m.Player, m.Move)
+		if errApply != nil {
+			return nil, errApply
+		}
+	}
+
+	g.deadline = snapshot.Deadline
+	_, g.done = board.Winner()
+
+	return g, nil
+}
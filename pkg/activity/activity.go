@@ -0,0 +1,183 @@
+package activity
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ Bus          = &bus{}
+	_ Subscription = &subscription{}
+)
+
+// Kind is name of account event, used as event name of sse stream.
+type Kind string
+
+const (
+	Deposit            Kind = "deposit"
+	WithdrawalPending  Kind = "withdrawal_pending"
+	WithdrawalFinished Kind = "withdrawal_finished"
+	// Settlement is result of the game for the player, lock of the game released with it
+	Settlement Kind = "settlement"
+	Lock       Kind = "lock"
+	Unlock     Kind = "unlock"
+)
+
+// Event is change of account balance, routed by account id or by wallet address for transactions.
+type Event struct {
+	Kind      Kind
+	AccountID string
+	Address   string
+	GameID    string
+	TicketID  string
+	// Amount in nano: stake for lock and unlock, result of the game for settlement (negative on loss), amount of transaction
+	Amount int64
+	// Tx json of transaction for deposit and withdrawal events
+	Tx        map[string]interface{}
+	Timestamp time.Time
+}
+
+type Subscription interface {
+	// Events closed after Close.
+	Events() <-chan *Event
+	// Dropped return number of oldest events dropped because buffer was full.
+	Dropped() uint64
+	Close()
+}
+
+// Bus deliver account events from database layer to subscribers, publisher never wait for subscriber.
+type Bus interface {
+	Publish(events ...*Event)
+	// Subscribe on events of the account, transaction events matched by address of the account.
+	Subscribe(accountID string, address string, buffer int) Subscription
+}
+
+type bus struct {
+	mutex     sync.RWMutex
+	byAccount map[string]map[*subscription]struct{}
+	byAddress map[string]map[*subscription]struct{}
+}
+
+// New create empty bus.
+func New() Bus {
+	return &bus{
+		byAccount: make(map[string]map[*subscription]struct{}),
+		byAddress: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+func (b *bus) Publish(events ...*Event) {
+	for _, event := range events {
+		if event.Timestamp.IsZero() {
+			event.Timestamp = time.Now()
+		}
+
+		b.mutex.RLock()
+		subs := b.byAccount[event.AccountID]
+		if event.AccountID == "" {
+			subs = b.byAddress[event.Address]
+		}
+		list := make([]*subscription, 0, len(subs))
+		for s := range subs {
+			list = append(list, s)
+		}
+		b.mutex.RUnlock()
+
+		for _, s := range list {
+			s.deliver(event)
+		}
+	}
+}
+
+func (b *bus) Subscribe(accountID string, address string, buffer int) Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	s := &subscription{
+		bus:       b,
+		accountID: accountID,
+		address:   address,
+		events:    make(chan *Event, buffer),
+	}
+
+	b.mutex.Lock()
+	add(b.byAccount, accountID, s)
+	add(b.byAddress, address, s)
+	b.mutex.Unlock()
+
+	return s
+}
+
+func add(index map[string]map[*subscription]struct{}, key string, s *subscription) {
+	if index[key] == nil {
+		index[key] = make(map[*subscription]struct{})
+	}
+	index[key][s] = struct{}{}
+}
+
+func remove(index map[string]map[*subscription]struct{}, key string, s *subscription) {
+	delete(index[key], s)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+type subscription struct {
+	bus       *bus
+	accountID string
+	address   string
+
+	// mutex serialize sends with close of events
+	mutex   sync.Mutex
+	events  chan *Event
+	closed  bool
+	dropped atomic.Uint64
+}
+
+func (s *subscription) Events() <-chan *Event {
+	return s.events
+}
+
+func (s *subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *subscription) Close() {
+	s.bus.mutex.Lock()
+	remove(s.bus.byAccount, s.accountID, s)
+	remove(s.bus.byAddress, s.address, s)
+	s.bus.mutex.Unlock()
+
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mutex.Unlock()
+}
+
+// deliver drop oldest event when buffer is full.
+func (s *subscription) deliver(event *Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	for {
+		select {
+		case s.events <- event:
+			return
+		default:
+		}
+
+		select {
+		case <-s.events:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
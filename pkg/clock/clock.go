@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"time"
+)
+
+var (
+	_ Clock = &realClock{}
+)
+
+// Clock is source of time for game timers.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct {
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (r *realTimer) C() <-chan time.Time {
+	return r.timer.C
+}
+
+func (r *realTimer) Stop() bool {
+	return r.timer.Stop()
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time {
+	return r.ticker.C
+}
+
+func (r *realTicker) Stop() {
+	r.ticker.Stop()
+}
+
+func (r *realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (r *realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{
+		timer: time.NewTimer(d),
+	}
+}
+
+func (r *realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{
+		ticker: time.NewTicker(d),
+	}
+}
+
+// New return clock based on time package.
+func New() Clock {
+	return &realClock{}
+}
@@ -0,0 +1,136 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	_ Clock = &Manual{}
+)
+
+// Manual is clock which moves only by Advance, timers fire instantly when deadline reached. Use for tests.
+type Manual struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock    *Manual
+	c        chan time.Time
+	deadline time.Time
+	// period is not zero for tickers
+	period time.Duration
+	active bool
+}
+
+func (m *manualTimer) C() <-chan time.Time {
+	return m.c
+}
+
+func (m *manualTimer) Stop() bool {
+	m.clock.mutex.Lock()
+	defer m.clock.mutex.Unlock()
+
+	wasActive := m.active
+	m.active = false
+	return wasActive
+}
+
+type manualTicker struct {
+	*manualTimer
+}
+
+func (m *manualTicker) Stop() {
+	m.manualTimer.Stop()
+}
+
+func (m *Manual) Now() time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.now
+}
+
+func (m *Manual) newTimer(d time.Duration, period time.Duration) *manualTimer {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	t := &manualTimer{
+		clock:    m,
+		c:        make(chan time.Time, 1),
+		deadline: m.now.Add(d),
+		period:   period,
+		active:   true,
+	}
+	m.timers = append(m.timers, t)
+	m.fire()
+
+	return t
+}
+
+func (m *Manual) NewTimer(d time.Duration) Timer {
+	return m.newTimer(d, 0)
+}
+
+func (m *Manual) NewTicker(d time.Duration) Ticker {
+	return &manualTicker{
+		manualTimer: m.newTimer(d, d),
+	}
+}
+
+// Advance move clock forward and fire all timers which deadline reached.
+func (m *Manual) Advance(d time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.now = m.now.Add(d)
+	m.fire()
+}
+
+// Timers return count of active timers, helps to wait until game loop started.
+func (m *Manual) Timers() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	count := 0
+	for _, t := range m.timers {
+		if t.active {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+func (m *Manual) fire() {
+	active := m.timers[:0]
+	for _, t := range m.timers {
+		for t.active && !t.deadline.After(m.now) {
+			// same as time package: skip tick if receiver is slow
+			select {
+			case t.c <- t.deadline:
+			default:
+			}
+
+			if t.period == 0 {
+				t.active = false
+				break
+			}
+			t.deadline = t.deadline.Add(t.period)
+		}
+
+		if t.active {
+			active = append(active, t)
+		}
+	}
+	m.timers = active
+}
+
+// NewManual return manual clock started from now.
+func NewManual(now time.Time) *Manual {
+	return &Manual{
+		now: now,
+	}
+}
@@ -2,7 +2,6 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
@@ -23,6 +22,62 @@ const (
 	MIN_GAME_DURATION = time.Second * 30
 	MAX_GAME_DURATION = time.Minute * 120
 
+	MIN_REVEAL_DURATION = time.Second * 15
+	MAX_REVEAL_DURATION = time.Minute * 10
+
+	MAX_DICE = 5
+
+	MIN_UNIQUE_NUMBER = 2
+	MAX_UNIQUE_NUMBER = 1000
+
+	MAX_TICKETS = 1000
+
+	MAX_CRASH_MULTIPLIER = 100
+	CRASH_TICK           = time.Millisecond * 200
+	CRASH_GROWTH         = 0.06
+	// CRASH_HOUSE_EDGE percent of every stake kept by house on average
+	CRASH_HOUSE_EDGE = 1
+
+	MIN_TURN_DURATION = time.Second * 5
+	MAX_TURN_DURATION = time.Minute * 5
+
+	MAX_TOURNAMENT_PLAYERS    = 32
+	MIN_REGISTRATION_DURATION = time.Minute
+	MAX_REGISTRATION_DURATION = time.Hour * 24
+	TOURNAMENT_MATCH_DURATION = time.Minute
+	TOURNAMENT_MATCH_RETRY    = time.Second * 30
+
+	QUICK_PLAY_PLAYERS       = 2
+	QUICK_PLAY_DURATION      = time.Second * 30
+	QUICK_PLAY_TURN_DURATION = time.Second * 30
+	QUICK_PLAY_TIMEOUT       = time.Minute * 2
+	// QUICK_PLAY_MAX_WAIT long polling of ticket, less than write timeout of http server
+	QUICK_PLAY_MAX_WAIT = time.Second * 8
+
+	MIN_SCHEDULE_INTERVAL = time.Minute
+
+	// HUB_STORE_BUFFER events of the game buffered for persistence, game wait only when store is behind by whole buffer
+	HUB_STORE_BUFFER = 1024
+	// HUB_SUBSCRIBER_BUFFER default buffer of other subscribers of the game events
+	HUB_SUBSCRIBER_BUFFER = 64
+	// WS_WRITE_TIMEOUT time for websocket client to accept one message
+	WS_WRITE_TIMEOUT = time.Second * 10
+	// WS_TICKET_TTL lifetime of ticket which authorize websocket and event stream connections
+	WS_TICKET_TTL = time.Second * 30
+
+	// ACTIVITY_BUFFER account events buffered for sse client, oldest dropped when client is slow
+	ACTIVITY_BUFFER   = 32
+	SSE_WRITE_TIMEOUT = time.Second * 10
+	// SSE_KEEP_ALIVE interval of comments which keep idle stream open behind proxies
+	SSE_KEEP_ALIVE = time.Second * 15
+
+	// MAX_RAKE percent of the bank which house can take on settlement
+	MAX_RAKE = 10
+
+	MAX_ROUNDS         = 9
+	MIN_ROUND_DURATION = time.Second * 10
+	MAX_ROUND_DURATION = time.Minute * 5
+
 	MIN_WITHDRAW_AMOUNT_FLOAT = float64(0.5)
 )
 
@@ -47,6 +102,8 @@ var Config = struct {
 	TonChainAddress string `env:"TON_CHAIN_ADDRESS" envDefault:"https://ton.org/global.config.json"`
 
 	TelegramBotToken string `env:"BOT_TOKEN,required"`
+	// TelegramAppLink link of mini app (https://t.me/<bot>/<app>), used for invite links
+	TelegramAppLink string `env:"TELEGRAM_APP_LINK"`
 
 	DefaultLastTx            uint64 `env:"DEFAULT_LAST_TX" envDefault:"48542810000001"`
 	Seed                     string `env:"WALLET_SEED"`
@@ -56,6 +113,12 @@ var Config = struct {
 	Port                     int    `env:"PORT" envDefault:"8081"`
 
 	SettingsID uint `env:"SETTINGS_ID" envDefault:"1"`
+	// HouseAccountID account credited with rake of games, games with rake not allowed without it
+	HouseAccountID string `env:"HOUSE_ACCOUNT_ID"`
+	// HouseRake json list of rake percents by game type, e.g. [{"game_type": 0, "rake": 2.5}], other game types without rake
+	HouseRake string `env:"HOUSE_RAKE"`
+	// HouseSchedule json list of recurring house games, e.g. [{"game_type": 0, "every": 300, "settings": {...}}]
+	HouseSchedule string `env:"HOUSE_SCHEDULE"`
 
 	PayloadSignatureKey string `env:"TONPROOF_PAYLOAD_SIGNATURE_KEY,required"`
 	ProofLifeTimeSec    int64  `env:"TONPROOF_PROOF_LIFETIME_SEC" envDefault:"300"`
@@ -66,10 +129,14 @@ var (
 	once = &sync.Once{}
 )
 
-func init() {
+// Load parse environment into Config, main call it before anything else.
+// Packages only read Config, so they can be imported and tested without environment of the server.
+func Load() error {
+	var err error
 	once.Do(func() {
-		if err := env.Parse(&Config); err != nil {
-			log.Fatalf("config parsing failed: %v\n", err)
+		err = env.Parse(&Config)
+		if err != nil {
+			return
 		}
 
 		if Config.Local {
@@ -82,4 +149,6 @@ func init() {
 			Config.ExampleDomain = Config.AppHost
 		}
 	})
+
+	return err
 }
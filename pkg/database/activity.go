@@ -0,0 +1,62 @@
+package database
+
+import (
+	"github.com/PxyUp/ton_games_example/pkg/activity"
+	"github.com/google/uuid"
+)
+
+// publish send events of committed changes to activity bus.
+func (g *gameDb) publish(events ...*activity.Event) {
+	g.activity.Publish(events...)
+}
+
+func txEvent(kind activity.Kind, rec TransactionRecord) *activity.Event {
+	return &activity.Event{
+		Kind:    kind,
+		Address: rec.GetAddress(),
+		Amount:  rec.GetAmount(),
+		Tx:      rec.JSON(),
+	}
+}
+
+func lockEvent(kind activity.Kind, l *lock) *activity.Event {
+	return &activity.Event{
+		Kind:      kind,
+		AccountID: l.AccountID.String(),
+		GameID:    idOf(l.GameID),
+		TicketID:  idOf(l.TicketID),
+		Amount:    int64(l.Amount),
+	}
+}
+
+func unlockEvents(locks []*lock) []*activity.Event {
+	events := make([]*activity.Event, len(locks))
+	for i, l := range locks {
+		events[i] = lockEvent(activity.Unlock, l)
+	}
+
+	return events
+}
+
+func settlementEvents(wins []*win) []*activity.Event {
+	events := make([]*activity.Event, len(wins))
+	for i, w := range wins {
+		events[i] = &activity.Event{
+			Kind:      activity.Settlement,
+			AccountID: w.AccountID.String(),
+			GameID:    w.GameID.String(),
+			Amount:    w.Amount,
+		}
+	}
+
+	return events
+}
+
+// idOf return empty string for null uuid.
+func idOf(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
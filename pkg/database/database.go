@@ -5,8 +5,10 @@ import (
 	"errors"
 	"runtime"
 
+	"github.com/PxyUp/ton_games_example/pkg/activity"
 	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 )
 
@@ -14,6 +16,8 @@ var (
 	ErrMissingPlayer    = errors.New("missing player by id")
 	ErrInternalDBError  = errors.New("internal error")
 	ErrTxRecordNotFound = errors.New("tx record not found")
+	// ErrInvalidHouseAccount returned on start when HOUSE_ACCOUNT_ID set but not id of existing account
+	ErrInvalidHouseAccount = errors.New("house account should be uuid of existing account")
 )
 
 func (g *gameDb) createUUIDExtension(ctx context.Context) error {
@@ -25,11 +29,13 @@ func (g *gameDb) createUUIDExtension(ctx context.Context) error {
 	return nil
 }
 
-func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint) (DB, error) {
+// New create database layer, committed balance changes published to the activity bus.
+func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint, bus activity.Bus) (DB, error) {
 	database := &gameDb{
 		logger:     logger,
 		settingsID: settingsID,
 		db:         db,
+		activity:   bus,
 	}
 
 	maxOpenConns := 4 * runtime.GOMAXPROCS(0)
@@ -74,6 +80,12 @@ func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint)
 			return nil, err
 		}
 
+		err = database.createSnapshotsTable(ctx)
+		if err != nil {
+			logger.Errorw("cant exec createSnapshotsTable", "error", err.Error())
+			return nil, err
+		}
+
 		err = database.createLockTable(ctx)
 		if err != nil {
 			logger.Errorw("cant exec createLockTable", "error", err.Error())
@@ -86,6 +98,12 @@ func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint)
 			return nil, err
 		}
 
+		err = database.createTournamentsTable(ctx)
+		if err != nil {
+			logger.Errorw("cant exec createTournamentsTable", "error", err.Error())
+			return nil, err
+		}
+
 		err = database.createTransactionsTable(ctx)
 		if err != nil {
 			logger.Errorw("cant exec createTransactionsTable", "error", err.Error())
@@ -107,6 +125,19 @@ func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint)
 		logger.Info("schema applied")
 	}
 
+	errHouse := database.checkHouseAccount(ctx)
+	if errHouse != nil {
+		logger.Errorw("cant check house account", "error", errHouse.Error())
+		return nil, errHouse
+	}
+
+	rakes, errRakes := parseRakes(config.Config.HouseRake)
+	if errRakes != nil {
+		logger.Errorw("cant parse house rake", "error", errRakes.Error())
+		return nil, errRakes
+	}
+	database.rakes = rakes
+
 	errUnlock := database.unlockAllInProgressGames(ctx)
 	if errUnlock != nil {
 		logger.Errorw("cant unlock games", "error", errUnlock.Error())
@@ -116,6 +147,29 @@ func New(ctx context.Context, db *bun.DB, logger logger.Logger, settingsID uint)
 	return database, nil
 }
 
+// checkHouseAccount refuse start with house account which can't receive rake and crash settlement, empty one disable them.
+func (g *gameDb) checkHouseAccount(ctx context.Context) error {
+	if config.Config.HouseAccountID == "" {
+		return nil
+	}
+
+	houseID, err := uuid.Parse(config.Config.HouseAccountID)
+	if err != nil {
+		return ErrInvalidHouseAccount
+	}
+
+	exists, err := g.db.NewSelect().Model((*account)(nil)).Where("id = ?", houseID).Exists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrInvalidHouseAccount
+	}
+
+	return nil
+}
+
 type preloadPair struct {
 	query string
 	args  []func(*bun.SelectQuery) *bun.SelectQuery
@@ -146,6 +200,9 @@ type DB interface {
 	AccountDB
 	GameDB
 	PaymentDB
+	SnapshotDB
+	TournamentDB
+	QueueDB
 }
 
 func (g *gameDb) hideError(err error) error {
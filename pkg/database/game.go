@@ -3,13 +3,17 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/activity"
 	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/payout"
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
 	"github.com/xssnick/tonutils-go/tlb"
@@ -22,23 +26,107 @@ var (
 
 var (
 	ErrMinimumWithdrawal        = fmt.Errorf("minimum withdrawal: %s", tlb.FromNanoTONU(uint64(config.MIN_WITHDRAW_AMOUNT)).String())
-	ErrMaxGamesInProgress       = fmt.Errorf("max games in progress: %d", config.Config.MaxGamesInProgress)
+	ErrMaxGamesInProgress       = errors.New("max games in progress")
 	ErrMaxPlayersInGame         = fmt.Errorf("max players in game")
-	ErrMaxPlayerGamesInProgress = fmt.Errorf("max games per player progress: %d", config.Config.MaxPlayerGamesInProgress)
+	ErrMaxPlayerGamesInProgress = errors.New("max games per player progress")
 	ErrSmallBalance             = errors.New("small balance")
 	ErrCreatorCantLeftGame      = errors.New("creator cant left game")
 	ErrCreatorCantJoinGame      = errors.New("creator already part of the game")
+	ErrPlayerAlreadyInGame      = errors.New("player already part of the game")
+	ErrOnlyCreatorCanCancel     = errors.New("only creator can cancel game")
+	ErrGameHasPlayers           = errors.New("game with players cant be canceled")
+	ErrGameNotActive            = errors.New("game is not active")
+	ErrHouseBankroll            = errors.New("house balance can't cover max payout of the game")
+	ErrHouseCantPlay            = errors.New("house account can't play against house")
 )
 
+// GameOption change game record on creation.
+type GameOption func(g *game)
+
+// Private hide game from lobby, players join it only with invite code.
+func Private(inviteCode string) GameOption {
+	return func(g *game) {
+		g.Private = true
+		g.InviteCode = inviteCode
+	}
+}
+
+// Hosted mark game of the house, creator not stake and not take seat.
+func Hosted() GameOption {
+	return func(g *game) {
+		g.House = true
+	}
+}
+
+// FromTickets seat players of matchmaking tickets on creation, hold of every ticket become stake of the game
+// in the same transaction. Ticket of the creator goes first, join called for every other player inside the transaction.
+func FromTickets(tickets []string, join func(playerID string) error) GameOption {
+	return func(g *game) {
+		g.tickets = tickets
+		g.join = join
+	}
+}
+
+// WithPayout set payout scheme of the game, weights in basis points used by top_n scheme.
+// Rake is not option of the creator, it set by operator for game type (HOUSE_RAKE).
+func WithPayout(scheme payout.Scheme, weights []uint64) GameOption {
+	return func(g *game) {
+		g.Payout = scheme.Name()
+		g.PayoutWeights = weights
+	}
+}
+
+// rakeEntry is rake of the game type set by operator.
+type rakeEntry struct {
+	GameType games.GameType `json:"game_type"`
+	Rake     float64        `json:"rake"`
+}
+
+// parseRakes read rakes of game types in basis points from json list of entries.
+func parseRakes(raw string) (map[games.GameType]uint16, error) {
+	rakes := make(map[games.GameType]uint16)
+	if raw == "" {
+		return rakes, nil
+	}
+
+	var entries []*rakeEntry
+	err := json.Unmarshal([]byte(raw), &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		factory, errFactory := games.GetFactory(entry.GameType)
+		if errFactory != nil {
+			return nil, errFactory
+		}
+
+		rake, errRake := payout.RakeBasisPoints(entry.Rake)
+		if errRake != nil {
+			return nil, fmt.Errorf("%s: %w", factory.Name(), errRake)
+		}
+
+		rakes[entry.GameType] = rake
+	}
+
+	return rakes, nil
+}
+
 type GameDB interface {
-	CreateGame(ctx context.Context, game games.Game) (GameRecord, error)
+	CreateGame(ctx context.Context, game games.Game, opts ...GameOption) (GameRecord, error)
 	GetGameById(ctx context.Context, gameId string, pairs ...*preloadPair) (GameRecord, error)
-	JoinGame(ctx context.Context, game games.Game, playerID string, cb func() error) (GameRecord, error)
+	// JoinGame lock stake of the player, staked games allow same player join again with additional stake.
+	JoinGame(ctx context.Context, game games.Game, playerID string, stake float64, cb func() error) (GameRecord, error)
 	LeftGame(ctx context.Context, game games.Game, playerID string, cb func() error) (GameRecord, error)
+	// CancelGame finish game of the creator without other players: state canceled, creator unlocked, cb abort the game after commit.
+	CancelGame(ctx context.Context, game games.Game, playerID string, cb func() error) (GameRecord, error)
 	AppendEvent(ctx context.Context, gameInstant games.Game, gevent games.GameEvent) (GameRecord, error)
 	ChangeGameState(ctx context.Context, game games.Game, state games.GameState) (GameRecord, error)
 	UnlockAllPlayer(ctx context.Context, game games.Game) (GameRecord, error)
-	StoreWinners(ctx context.Context, game games.Game, winners []games.Player) (GameRecord, error)
+	// StoreWinners settle the bank by payout scheme of the game, ranking optional and used by schemes which pay not only to winners.
+	// Games played against house (games.HouseBanked) pay games.Payout of winners, rest of the bank goes to house.
+	StoreWinners(ctx context.Context, game games.Game, winners []games.Player, ranking [][]games.Player) (GameRecord, error)
+	// GetActiveGames return public games in lobby or in progress.
 	GetActiveGames(ctx context.Context, gameType games.GameType) ([]GameRecord, error)
 }
 
@@ -46,13 +134,30 @@ type gameDb struct {
 	settingsID uint
 	logger     logger.Logger
 	db         *bun.DB
+	activity   activity.Bus
+	// rakes in basis points by game type, applied to every created game
+	rakes map[games.GameType]uint16
 }
 
 func (g *gameDb) unlockAllInProgressGames(ctx context.Context) error {
 	return g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
 		gList := []*game{}
 
-		errGames := g.db.NewSelect().Model(&gList).Column("id").Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated})).Scan(ctx)
+		_, errSnapshots := tx.NewDelete().Model((*snapshot)(nil)).Where("game_id NOT IN (?)", tx.NewSelect().Model((*game)(nil)).Column("id").Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated}))).Exec(ctx)
+		if errSnapshots != nil {
+			g.logger.Errorw("cant delete snapshots of finished games", "error", errSnapshots.Error())
+			return errSnapshots
+		}
+
+		// matchmaking queue not survive restart
+		_, errQueue := tx.NewDelete().Model((*lock)(nil)).Where("ticket_id IS NOT NULL").Exec(ctx)
+		if errQueue != nil {
+			g.logger.Errorw("cant delete matchmaking locks", "error", errQueue.Error())
+			return errQueue
+		}
+
+		// games with snapshot will be restored by runtime
+		errGames := g.db.NewSelect().Model(&gList).Column("id").Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated})).Where("id NOT IN (?)", tx.NewSelect().Model((*snapshot)(nil)).Column("game_id")).Scan(ctx)
 		if errGames != nil {
 			g.logger.Errorf("cant select games in states: %v", []games.GameState{games.GameInProgress, games.GameCreated})
 			return errGames
@@ -91,7 +196,7 @@ func (g *gameDb) unlockAllInProgressGames(ctx context.Context) error {
 func (g *gameDb) GetActiveGames(ctx context.Context, gameType games.GameType) ([]GameRecord, error) {
 	gList := []*game{}
 
-	errList := g.db.NewSelect().Model(&gList).Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated})).Where("type = ?", gameType).Order("created_at desc").Relation("Players", func(q *bun.SelectQuery) *bun.SelectQuery {
+	errList := g.db.NewSelect().Model(&gList).Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated})).Where("type = ?", gameType).Where("private = ?", false).Order("created_at desc").Relation("Players", func(q *bun.SelectQuery) *bun.SelectQuery {
 		return q.Column("id")
 	}).Scan(ctx)
 	if errList != nil {
@@ -123,12 +228,20 @@ func (g *gameDb) GetActiveGames(ctx context.Context, gameType games.GameType) ([
 	return gr, nil
 }
 
-func (g *gameDb) StoreWinners(ctx context.Context, gameInstant games.Game, winnersList []games.Player) (GameRecord, error) {
+func (g *gameDb) StoreWinners(ctx context.Context, gameInstant games.Game, winnersList []games.Player, ranking [][]games.Player) (GameRecord, error) {
 	gameIdUuid, err := uuid.Parse(gameInstant.GetID())
 	if err != nil {
 		return nil, g.hideError(err)
 	}
+
+	var settled []*win
 	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		gameLocks := []*lock{}
+		errLocks := tx.NewSelect().Model(&gameLocks).Column("account_id", "amount").Where("game_id = ?", gameIdUuid).Scan(ctx)
+		if errLocks != nil {
+			return errLocks
+		}
+
 		_, errDeleteLock := tx.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Exec(ctx)
 		if errDeleteLock != nil {
 			return errDeleteLock
@@ -142,71 +255,156 @@ func (g *gameDb) StoreWinners(ctx context.Context, gameInstant games.Game, winne
 			return errGamePlayer
 		}
 
-		var losers []*win
-		var winners []*win
-
-		gamePrice := uint64(time.Duration(gameInstant.GetCost() * float64(time.Second)))
-		bank := gamePrice * uint64(len(gameDao.Players))
+		_, banked := gameInstant.(games.HouseBanked)
 
-		winnerGet := bank/uint64(len(winnersList)) - gamePrice
+		bank := uint64(0)
+		stakes := make(map[uuid.UUID]uint64, len(gameLocks))
+		for _, l := range gameLocks {
+			// max payout locked by house is not part of the bank
+			if banked && l.AccountID.String() == config.Config.HouseAccountID {
+				continue
+			}
+			stakes[l.AccountID] = l.Amount
+			bank += l.Amount
+		}
 
 		timeNow := time.Now()
+		var all []*win
 
-		for _, p := range gameDao.Players {
-			exists := false
+		// result of the house: rake, or for games against house stakes of losers minus excess paid to winners
+		house := int64(0)
+		payouts := make(map[string]uint64)
+		if banked {
+			house = int64(bank)
 			for _, winner := range winnersList {
-				if p.ID.String() == winner.GetId() {
-					exists = true
-					break
+				if direct, ok := winner.(games.Payout); ok {
+					payouts[winner.GetId()] = uint64(time.Duration(direct.GetPayout() * float64(time.Second)))
+					house -= int64(payouts[winner.GetId()])
 				}
 			}
+		} else {
+			scheme, errScheme := payoutScheme(gameDao)
+			if errScheme != nil {
+				return errScheme
+			}
+
+			rake := payout.Rake(bank, gameDao.Rake)
+			house = int64(rake)
+
+			payouts = scheme.Distribute(bank-rake, rankingOf(winnersList, ranking))
+		}
 
-			if exists {
-				winners = append(winners, &win{
-					GameID:    gameIdUuid,
-					AccountID: p.ID,
-					Amount:    int64(winnerGet),
-					CreatedAt: timeNow,
-					UpdatedAt: timeNow,
-				})
-			} else {
-				losers = append(losers, &win{
-					GameID:    gameIdUuid,
-					AccountID: p.ID,
-					Amount:    -int64(gamePrice),
-					CreatedAt: timeNow,
-					UpdatedAt: timeNow,
-				})
+		if house != 0 {
+			houseID, errHouse := houseAccount()
+			if errHouse != nil {
+				return errHouse
 			}
+
+			all = append(all, &win{
+				GameID:    gameIdUuid,
+				AccountID: houseID,
+				Amount:    house,
+				CreatedAt: timeNow,
+				UpdatedAt: timeNow,
+			})
 		}
 
-		all := append(winners, losers...)
+		for _, p := range gameDao.Players {
+			amount := -int64(stakes[p.ID])
+			if winnerGet, ok := payouts[p.ID.String()]; ok {
+				amount += int64(winnerGet)
+			}
+
+			// house account can be a player of the game, only one result per account
+			if len(all) > 0 && all[0].AccountID == p.ID {
+				all[0].Amount += amount
+				continue
+			}
+
+			all = append(all, &win{
+				GameID:    gameIdUuid,
+				AccountID: p.ID,
+				Amount:    amount,
+				CreatedAt: timeNow,
+				UpdatedAt: timeNow,
+			})
+		}
 
 		_, errInsert := tx.NewInsert().Model(&all).Exec(ctx)
 		if errInsert != nil {
 			return errInsert
 		}
 
+		settled = all
 		return nil
 	})
 	if err != nil {
 		return nil, g.hideError(err)
 	}
 
+	g.publish(settlementEvents(settled)...)
+
 	return g.GetGameById(ctx, gameInstant.GetID())
 }
 
+func payoutScheme(dao *game) (payout.Scheme, error) {
+	weights := make([]float64, len(dao.PayoutWeights))
+	for i, weight := range dao.PayoutWeights {
+		weights[i] = float64(weight) / 100
+	}
+
+	return payout.New(dao.Payout, weights)
+}
+
+// rankingOf return ranking with winners as first place, rest of players by ranking of the game.
+// Players inside of the place ordered by id, so dust always goes to the same player.
+func rankingOf(winnersList []games.Player, ranking [][]games.Player) [][]string {
+	isWinner := make(map[string]bool, len(winnersList))
+	first := make([]string, 0, len(winnersList))
+	for _, winner := range winnersList {
+		if isWinner[winner.GetId()] {
+			continue
+		}
+		isWinner[winner.GetId()] = true
+		first = append(first, winner.GetId())
+	}
+	sort.Strings(first)
+
+	var result [][]string
+	if len(first) > 0 {
+		result = append(result, first)
+	}
+	for _, group := range ranking {
+		var place []string
+		for _, p := range group {
+			if !isWinner[p.GetId()] {
+				place = append(place, p.GetId())
+			}
+		}
+
+		if len(place) > 0 {
+			sort.Strings(place)
+			result = append(result, place)
+		}
+	}
+
+	return result
+}
+
 func (g *gameDb) UnlockAllPlayer(ctx context.Context, gameInstant games.Game) (GameRecord, error) {
 	gameIdUuid, err := uuid.Parse(gameInstant.GetID())
 	if err != nil {
 		return nil, g.hideError(err)
 	}
 
-	_, errDelete := g.db.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Exec(ctx)
+	unlocked := []*lock{}
+	_, errDelete := g.db.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Returning("*").Exec(ctx, &unlocked)
 	if errDelete != nil {
 		return nil, g.hideError(errDelete)
 	}
 
+	g.publish(unlockEvents(unlocked)...)
+
 	return g.GetGameById(ctx, gameInstant.GetID())
 }
 
@@ -230,12 +428,18 @@ func (g *gameDb) AppendEvent(ctx context.Context, gameInstant games.Game, gevent
 		return nil, g.hideError(err)
 	}
 
+	players := make([]string, len(gevent.Players()))
+	for i, p := range gevent.Players() {
+		players[i] = p.GetId()
+	}
+
 	_, errAppend := g.db.NewInsert().Model(&history{
 		GameID:    gameIdUuid,
 		Timestamp: gevent.GetTimeStamp(),
 		Message:   gevent.Msg(),
 		Type:      gevent.GetEventType(),
 		MD:        gevent.GetMD(),
+		Players:   players,
 	}).Exec(ctx)
 	if errAppend != nil {
 		return nil, g.hideError(errAppend)
@@ -259,8 +463,9 @@ func (g *gameDb) LeftGame(ctx context.Context, gameInstant games.Game, playerID
 		return nil, g.hideError(err)
 	}
 
+	unlocked := []*lock{}
 	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
-		_, errLock := tx.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Where("account_id = ?", playerIDUuid).Exec(ctx)
+		_, errLock := tx.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Where("account_id = ?", playerIDUuid).Returning("*").Exec(ctx, &unlocked)
 		if errLock != nil {
 			return errLock
 		}
@@ -281,15 +486,23 @@ func (g *gameDb) LeftGame(ctx context.Context, gameInstant games.Game, playerID
 		return nil, g.hideError(err)
 	}
 
+	g.publish(unlockEvents(unlocked)...)
+
 	return g.GetGameById(ctx, gameInstant.GetID())
 }
 
-func (g *gameDb) JoinGame(ctx context.Context, gameInstant games.Game, playerID string, cb func() error) (GameRecord, error) {
-	if playerID == gameInstant.GetCreator() {
+func (g *gameDb) JoinGame(ctx context.Context, gameInstant games.Game, playerID string, stake float64, cb func() error) (GameRecord, error) {
+	_, staked := gameInstant.(games.Staked)
+
+	if playerID == gameInstant.GetCreator() && !staked {
 		return nil, ErrCreatorCantJoinGame
 	}
 
-	valid, cost, err := g.canPlayerJoinGame(ctx, gameInstant, playerID)
+	if _, banked := gameInstant.(games.HouseBanked); banked && playerID == config.Config.HouseAccountID {
+		return nil, ErrHouseCantPlay
+	}
+
+	valid, cost, err := g.canPlayerJoinGame(ctx, stake, playerID)
 	if err != nil {
 		return nil, g.hideError(err)
 	}
@@ -309,6 +522,40 @@ func (g *gameDb) JoinGame(ctx context.Context, gameInstant games.Game, playerID
 	}
 
 	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		// serialize with cancel of the game
+		errGameLock := tx.NewSelect().Model((*game)(nil)).Column("id").Where("id = ?", gameIdUuid).For("UPDATE").Scan(ctx)
+		if errGameLock != nil {
+			return errGameLock
+		}
+
+		// balance checked before transaction can be spent by concurrent stakes of the player, e.g. tickets of jackpot
+		balance, errBalance := lockBalance(ctx, tx, playerIDUuid)
+		if errBalance != nil {
+			return errBalance
+		}
+
+		if balance.Available() < cost {
+			return ErrSmallBalance
+		}
+
+		joined, errJoined := tx.NewSelect().Model((*accountGame)(nil)).Where("game_id = ?", gameIdUuid).Where("account_id = ?", playerIDUuid).Exists(ctx)
+		if errJoined != nil {
+			return errJoined
+		}
+
+		if joined {
+			if !staked {
+				return ErrPlayerAlreadyInGame
+			}
+
+			_, errLockUpdate := tx.NewUpdate().Model((*lock)(nil)).Set("amount = amount + ?", cost).Where("game_id = ?", gameIdUuid).Where("account_id = ?", playerIDUuid).Exec(ctx)
+			if errLockUpdate != nil {
+				return errLockUpdate
+			}
+
+			return cb()
+		}
+
 		count, errCount := tx.NewSelect().Model((*accountGame)(nil)).Where("game_id = ?", gameIdUuid).Count(ctx)
 		if errCount != nil {
 			return errCount
@@ -343,9 +590,78 @@ func (g *gameDb) JoinGame(ctx context.Context, gameInstant games.Game, playerID
 		return nil
 	})
 	if err != nil {
+		return nil, g.hideGameError(err)
+	}
+
+	g.publish(lockEvent(activity.Lock, &lock{
+		GameID:    gameIdUuid,
+		AccountID: playerIDUuid,
+		Amount:    cost,
+	}))
+
+	return g.GetGameById(ctx, gameInstant.GetID())
+}
+
+func (g *gameDb) CancelGame(ctx context.Context, gameInstant games.Game, playerID string, cb func() error) (GameRecord, error) {
+	if playerID != gameInstant.GetCreator() {
+		return nil, ErrOnlyCreatorCanCancel
+	}
+
+	gameIdUuid, err := uuid.Parse(gameInstant.GetID())
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	unlocked := []*lock{}
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		gameDao := &game{}
+		errGame := tx.NewSelect().Model(gameDao).Column("id", "state", "house").Where("id = ?", gameIdUuid).For("UPDATE").Scan(ctx)
+		if errGame != nil {
+			return errGame
+		}
+
+		if gameDao.State != games.GameCreated && gameDao.State != games.GameInProgress {
+			return ErrGameNotActive
+		}
+
+		count, errCount := tx.NewSelect().Model((*accountGame)(nil)).Where("game_id = ?", gameIdUuid).Count(ctx)
+		if errCount != nil {
+			return errCount
+		}
+
+		// creator of house game not take seat
+		seats := 1
+		if gameDao.House {
+			seats = 0
+		}
+
+		if count > seats {
+			return ErrGameHasPlayers
+		}
+
+		_, errUpdate := tx.NewUpdate().Model((*game)(nil)).Set("state = ?", games.GameCanceled).Set("updated_at = ?", time.Now()).Where("id = ?", gameIdUuid).Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		_, errLock := tx.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Returning("*").Exec(ctx, &unlocked)
+		return errLock
+	})
+	if err != nil {
+		if errors.Is(err, ErrGameHasPlayers) || errors.Is(err, ErrGameNotActive) {
+			return nil, err
+		}
 		return nil, g.hideError(err)
 	}
 
+	g.publish(unlockEvents(unlocked)...)
+
+	// abort only after cancel committed, otherwise game stopped in memory while record still created with locks
+	errCb := cb()
+	if errCb != nil {
+		return nil, g.hideError(errCb)
+	}
+
 	return g.GetGameById(ctx, gameInstant.GetID())
 }
 
@@ -365,19 +681,59 @@ func (g *gameDb) GetGameById(ctx context.Context, gameId string, pairs ...*prelo
 	return g.gameFromDao(ctx, gr)
 }
 
-func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game) (GameRecord, error) {
-	valid, cost, err := g.canPlayerJoinGame(ctx, gameInstant, gameInstant.GetCreator())
+func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game, opts ...GameOption) (GameRecord, error) {
+	gameIdUuid, err := uuid.Parse(gameInstant.GetID())
 	if err != nil {
 		return nil, g.hideError(err)
 	}
 
-	if !valid {
-		return nil, ErrSmallBalance
+	timeNow := time.Now()
+	gr := &game{
+		ID:         gameIdUuid,
+		CreatedAt:  timeNow,
+		UpdatedAt:  timeNow,
+		Creator:    gameInstant.GetCreator(),
+		Cost:       uint64(time.Duration(gameInstant.GetCost() * float64(time.Second))),
+		MaxPlayers: gameInstant.GetMaxPlayers(),
+		Duration:   gameInstant.GetDuration(),
+		Type:       gameInstant.GameType(),
+		State:      games.GameCreated,
+		Payout:     payout.Split,
 	}
 
-	gameIdUuid, err := uuid.Parse(gameInstant.GetID())
-	if err != nil {
-		return nil, g.hideError(err)
+	if configurable, ok := gameInstant.(games.Configurable); ok {
+		gr.Settings = configurable.GetSettings()
+	}
+
+	for _, opt := range opts {
+		opt(gr)
+	}
+
+	// games against house settled without rake
+	if _, banked := gameInstant.(games.HouseBanked); !banked {
+		gr.Rake = g.rakes[gr.Type]
+	}
+
+	cost := uint64(0)
+	if len(gr.tickets) > 0 {
+		// holds of the tickets checked in transaction
+		cost = uint64(time.Duration(gameInstant.GetCost() * float64(time.Second)))
+	} else if !gr.House {
+		stake := gameInstant.GetCost()
+		if staked, ok := gameInstant.(games.Staked); ok {
+			stake = staked.StakeOf(gameInstant.GetCreator())
+		}
+
+		valid, creatorCost, errBalance := g.canPlayerJoinGame(ctx, stake, gameInstant.GetCreator())
+		if errBalance != nil {
+			return nil, g.hideError(errBalance)
+		}
+
+		if !valid {
+			return nil, ErrSmallBalance
+		}
+
+		cost = creatorCost
 	}
 
 	creatorIDUuid, err := uuid.Parse(gameInstant.GetCreator())
@@ -395,13 +751,17 @@ func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game) (GameRe
 		}
 
 		if count >= int(config.Config.MaxGamesInProgress) {
-			return ErrMaxGamesInProgress
+			return fmt.Errorf("%w: %d", ErrMaxGamesInProgress, config.Config.MaxGamesInProgress)
 		}
 
 		return nil
 	})
 
 	errGr.Go(func() error {
+		if gr.House {
+			return nil
+		}
+
 		listGames := []*game{}
 		count, errCount := g.db.NewSelect().Model(&listGames).Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated})).Where("creator = ?", creatorIDUuid).Count(ctx)
 		if errCount != nil {
@@ -409,7 +769,7 @@ func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game) (GameRe
 		}
 
 		if count >= int(config.Config.MaxPlayerGamesInProgress) {
-			return ErrMaxPlayerGamesInProgress
+			return fmt.Errorf("%w: %d", ErrMaxPlayerGamesInProgress, config.Config.MaxPlayerGamesInProgress)
 		}
 
 		return nil
@@ -420,30 +780,53 @@ func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game) (GameRe
 		return nil, g.hideError(errGroupErr)
 	}
 
+	var released, locked []*lock
 	errTx := g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
-		timeNow := time.Now()
-		gr := &game{
-			ID:         gameIdUuid,
-			CreatedAt:  timeNow,
-			UpdatedAt:  timeNow,
-			Creator:    gameInstant.GetCreator(),
-			Cost:       cost,
-			MaxPlayers: gameInstant.GetMaxPlayers(),
-			Duration:   gameInstant.GetDuration(),
-			Type:       gameInstant.GameType(),
-			State:      games.GameCreated,
-		}
-
 		_, errCreated := tx.NewInsert().Model(gr).Exec(ctx)
 		if errCreated != nil {
 			return errCreated
 		}
 
-		_, errLockAppend := tx.NewInsert().Model(&lock{
+		house := uuid.Nil
+		if banked, ok := gameInstant.(games.HouseBanked); ok {
+			exposure, errExposure := lockHouse(ctx, tx, gr, banked)
+			if errExposure != nil {
+				return errExposure
+			}
+			house = exposure.AccountID
+			locked = append(locked, exposure)
+		}
+
+		// house not play own game
+		if gr.House {
+			return nil
+		}
+
+		if len(gr.tickets) > 0 {
+			seatReleased, seated, errSeat := seatTickets(ctx, tx, gr, cost, house)
+			if errSeat != nil {
+				return errSeat
+			}
+			released = seatReleased
+			locked = append(locked, seated...)
+			return nil
+		}
+
+		balance, errBalance := lockBalance(ctx, tx, creatorIDUuid)
+		if errBalance != nil {
+			return errBalance
+		}
+
+		if balance.Available() < cost {
+			return ErrSmallBalance
+		}
+
+		creatorLock := &lock{
 			GameID:    gameIdUuid,
 			AccountID: creatorIDUuid,
 			Amount:    cost,
-		}).Exec(ctx)
+		}
+		_, errLockAppend := tx.NewInsert().Model(creatorLock).Exec(ctx)
 		if errLockAppend != nil {
 			return errLockAppend
 		}
@@ -456,16 +839,79 @@ func (g *gameDb) CreateGame(ctx context.Context, gameInstant games.Game) (GameRe
 			return errGameAccount
 		}
 
+		locked = append(locked, creatorLock)
 		return nil
 	})
 	if errTx != nil {
-		return nil, g.hideError(errTx)
+		return nil, g.hideGameError(errTx)
+	}
+
+	g.publish(unlockEvents(released)...)
+	for _, l := range locked {
+		g.publish(lockEvent(activity.Lock, l))
 	}
 
 	return g.GetGameById(ctx, gameInstant.GetID())
 }
 
-func (g *gameDb) canPlayerJoinGame(ctx context.Context, gameInstant games.Game, playerId string) (bool, uint64, error) {
+// hideGameError pass known errors of the game to the caller, other errors hidden.
+func (g *gameDb) hideGameError(err error) error {
+	for _, known := range []error{
+		ErrSmallBalance,
+		ErrHouseBankroll,
+		ErrHouseCantPlay,
+	} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+
+	return g.hideError(err)
+}
+
+func houseAccount() (uuid.UUID, error) {
+	houseID, err := uuid.Parse(config.Config.HouseAccountID)
+	if err != nil {
+		return uuid.Nil, payout.ErrNoHouseAccount
+	}
+
+	return houseID, nil
+}
+
+// lockHouse lock max payout of the game on house account, so house balance never goes below zero after settlement.
+func lockHouse(ctx context.Context, tx bun.Tx, gr *game, banked games.HouseBanked) (*lock, error) {
+	houseID, err := houseAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	if gr.Creator == houseID.String() && !gr.House {
+		return nil, ErrHouseCantPlay
+	}
+
+	balance, err := lockBalance(ctx, tx, houseID)
+	if err != nil {
+		return nil, err
+	}
+
+	exposure := &lock{
+		GameID:    gr.ID,
+		AccountID: houseID,
+		Amount:    uint64(time.Duration(banked.MaxPayout() * float64(time.Second))),
+	}
+	if balance.Available() < exposure.Amount {
+		return nil, ErrHouseBankroll
+	}
+
+	_, errLock := tx.NewInsert().Model(exposure).Exec(ctx)
+	if errLock != nil {
+		return nil, errLock
+	}
+
+	return exposure, nil
+}
+
+func (g *gameDb) canPlayerJoinGame(ctx context.Context, stake float64, playerId string) (bool, uint64, error) {
 	playerIDUuid, err := uuid.Parse(playerId)
 	if err != nil {
 		return false, 0, g.hideError(err)
@@ -476,7 +922,7 @@ func (g *gameDb) canPlayerJoinGame(ctx context.Context, gameInstant games.Game,
 		return false, 0, g.hideError(err)
 	}
 
-	floatCost := uint64(time.Duration(gameInstant.GetCost() * float64(time.Second)))
+	floatCost := uint64(time.Duration(stake * float64(time.Second)))
 
 	if balance.Available() < floatCost {
 		return false, 0, nil
@@ -20,6 +20,18 @@ type GameRecord interface {
 	GetPlayers() []string
 	GetMaxPlayers() uint8
 	GetCreator() string
+	GetGameType() games.GameType
+	GetEvents() []*games.RecordedEvent
+	// GetPayouts return settled result of the game in nano by account id (house rake included), filled only with Winners preload.
+	GetPayouts() map[string]int64
+	IsPrivate() bool
+	// IsHosted return true for game of the house, creator of such game is not a player.
+	IsHosted() bool
+	// GetInviteCode return invite code of private game, should be shown only to creator.
+	GetInviteCode() string
+	GetPayout() string
+	// GetRake return rake of the game in percents.
+	GetRake() float64
 	JSON() map[string]interface{}
 }
 
@@ -28,6 +40,7 @@ type historyRecord struct {
 	HType     games.GameEventType `json:"type"`
 	Msg       string              `json:"msg"`
 	MD        games.GameMD        `json:"metadata"`
+	Players   []string            `json:"players,omitempty"`
 }
 
 type gameRecord struct {
@@ -42,6 +55,41 @@ type gameRecord struct {
 
 	history  []*historyRecord
 	gameType games.GameType
+	settings games.GameMD
+
+	private    bool
+	inviteCode string
+	house      bool
+
+	payouts map[string]int64
+
+	payout        string
+	payoutWeights []float64
+	rake          float64
+}
+
+func (g *gameRecord) GetPayout() string {
+	return g.payout
+}
+
+func (g *gameRecord) GetRake() float64 {
+	return g.rake
+}
+
+func (g *gameRecord) IsPrivate() bool {
+	return g.private
+}
+
+func (g *gameRecord) GetPayouts() map[string]int64 {
+	return g.payouts
+}
+
+func (g *gameRecord) IsHosted() bool {
+	return g.house
+}
+
+func (g *gameRecord) GetInviteCode() string {
+	return g.inviteCode
 }
 
 func (g *gameRecord) GetState() games.GameState {
@@ -68,6 +116,21 @@ func (g *gameRecord) GetGameType() games.GameType {
 	return g.gameType
 }
 
+func (g *gameRecord) GetEvents() []*games.RecordedEvent {
+	events := make([]*games.RecordedEvent, len(g.history))
+	for i, h := range g.history {
+		events[i] = &games.RecordedEvent{
+			Type:      h.HType,
+			Timestamp: h.Timestamp,
+			Msg:       h.Msg,
+			MD:        h.MD,
+			Players:   h.Players,
+		}
+	}
+
+	return events
+}
+
 func (g *gameRecord) JSON() map[string]interface{} {
 	timeLeft := int(time.Until(g.GetCreationTime().Add(g.duration)).Seconds())
 	if timeLeft < 0 {
@@ -85,6 +148,14 @@ func (g *gameRecord) JSON() map[string]interface{} {
 		"max_players":   g.GetMaxPlayers(),
 		"creator":       g.GetCreator(),
 		"game_type":     g.GetGameType(),
+		"settings":      g.settings,
+		"private":       g.IsPrivate(),
+		"house":         g.IsHosted(),
+		"payout": map[string]interface{}{
+			"scheme":  g.GetPayout(),
+			"weights": g.payoutWeights,
+			"rake":    g.GetRake(),
+		},
 	}
 }
 
@@ -110,6 +181,7 @@ func (g *gameDb) gameFromDao(ctx context.Context, dao *game) (*gameRecord, error
 			HType:     record.Type,
 			Msg:       record.Message,
 			MD:        record.MD,
+			Players:   record.Players,
 		}
 	}
 
@@ -124,6 +196,23 @@ func (g *gameDb) gameFromDao(ctx context.Context, dao *game) (*gameRecord, error
 		history:      hrs,
 		creator:      dao.Creator,
 		gameType:     dao.Type,
+		settings:     dao.Settings,
+		private:      dao.Private,
+		inviteCode:   dao.InviteCode,
+		house:        dao.House,
+		payout:       dao.Payout,
+		rake:         float64(dao.Rake) / 100,
+	}
+
+	if dao.Winners != nil {
+		gr.payouts = make(map[string]int64, len(dao.Winners))
+		for _, w := range dao.Winners {
+			gr.payouts[w.AccountID.String()] = w.Amount
+		}
+	}
+
+	for _, weight := range dao.PayoutWeights {
+		gr.payoutWeights = append(gr.payoutWeights, float64(weight)/100)
 	}
 
 	return gr, nil
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/payout"
+	"github.com/stretchr/testify/require"
+)
+
+func players(ids ...string) []games.Player {
+	result := make([]games.Player, len(ids))
+	for i, id := range ids {
+		result[i] = &games.BasePlayer{Id: id}
+	}
+
+	return result
+}
+
+func TestRankingOfDustToLowestId(t *testing.T) {
+	ranking := rankingOf(players("c", "a", "b", "a"), [][]games.Player{players("b", "c", "a"), players("e", "d")})
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e"}}, ranking)
+
+	scheme, err := payout.New(payout.Split, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"a": 4, "b": 3, "c": 3}, scheme.Distribute(10, ranking))
+}
+
+func TestRankingOfWithoutWinners(t *testing.T) {
+	require.Empty(t, rankingOf(nil, nil))
+	require.Equal(t, [][]string{{"a", "b"}}, rankingOf(nil, [][]games.Player{players("b", "a")}))
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/PxyUp/ton_games_example/games"
@@ -19,6 +20,15 @@ const (
 
 type TxType int8
 
+type TournamentState int8
+
+const (
+	TournamentRegistration TournamentState = iota
+	TournamentRunning
+	TournamentFinished
+	TournamentCanceled
+)
+
 const (
 	In TxType = iota
 	Out
@@ -111,6 +121,69 @@ func (g *gameDb) createGamesTable(ctx context.Context) error {
 		return err
 	}
 
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("settings jsonb").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("private boolean NOT NULL DEFAULT false").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("invite_code varchar").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("payout varchar NOT NULL DEFAULT 'split'").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("payout_weights jsonb").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("rake integer NOT NULL DEFAULT 0").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*game)(nil)).
+		ColumnExpr("house boolean NOT NULL DEFAULT false").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -133,6 +206,20 @@ type game struct {
 	Duration   time.Duration   `bun:"duration,notnull"`
 	Type       games.GameType  `bun:"type,notnull"`
 	State      games.GameState `bun:"state,notnull"`
+	Settings   games.GameMD    `bun:"settings,type:jsonb"`
+	Private    bool            `bun:"private,notnull,default:false"`
+	InviteCode string          `bun:"invite_code,nullzero"`
+	// Payout scheme of settlement, PayoutWeights in basis points used by top_n
+	Payout        string   `bun:"payout,notnull,default:'split'"`
+	PayoutWeights []uint64 `bun:"payout_weights,type:jsonb"`
+	// Rake of the bank in basis points credited to house account
+	Rake uint16 `bun:"rake,notnull,default:0"`
+	// House game created by schedule, creator is house account without seat and stake
+	House bool `bun:"house,notnull,default:false"`
+
+	// tickets of matched players seated on creation and join of every player except creator, not stored
+	tickets []string                    `bun:"-"`
+	join    func(playerID string) error `bun:"-"`
 }
 
 func (g *gameDb) createHistoryTable(ctx context.Context) error {
@@ -156,6 +243,15 @@ func (g *gameDb) createHistoryTable(ctx context.Context) error {
 		return err
 	}
 
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*history)(nil)).
+		ColumnExpr("players jsonb").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -169,6 +265,154 @@ type history struct {
 	Message   string              `bun:"message,notnull"`
 	Type      games.GameEventType `bun:"type,notnull"`
 	MD        games.GameMD        `bun:"type:jsonb"`
+	// Players ids of the event, e.g. joined player or winners
+	Players []string `bun:"players,type:jsonb"`
+}
+
+func (g *gameDb) createSnapshotsTable(ctx context.Context) error {
+	_, err := g.db.NewCreateTable().
+		IfNotExists().
+		Model((*snapshot)(nil)).
+		WithForeignKeys().
+		ForeignKey(`("game_id") REFERENCES "games" ("id") ON DELETE CASCADE`).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type snapshot struct {
+	bun.BaseModel `bun:"table:snapshots"`
+
+	GameID    uuid.UUID `bun:"type:uuid,pk"`
+	UpdatedAt time.Time `bun:"updated_at,notnull"`
+
+	Type     games.GameType  `bun:"type,notnull"`
+	Creator  string          `bun:"type:uuid,notnull"`
+	Deadline time.Time       `bun:"deadline,notnull"`
+	State    json.RawMessage `bun:"state,type:jsonb,notnull"`
+}
+
+func (g *gameDb) createTournamentsTable(ctx context.Context) error {
+	_, err := g.db.NewCreateTable().
+		IfNotExists().
+		Model((*tournament)(nil)).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateIndex().
+		IfNotExists().
+		Model((*tournament)(nil)).
+		Index("idx_tournaments_state").
+		Column("state").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateTable().
+		IfNotExists().
+		Model((*tournamentEntry)(nil)).
+		WithForeignKeys().
+		ForeignKey(`("tournament_id") REFERENCES "tournaments" ("id") ON DELETE CASCADE`).
+		ForeignKey(`("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE`).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateIndex().
+		IfNotExists().
+		Model((*tournamentEntry)(nil)).
+		Index("idx_tournament_entries_account_id").
+		Column("account_id").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateTable().
+		IfNotExists().
+		Model((*tournamentMatch)(nil)).
+		WithForeignKeys().
+		ForeignKey(`("tournament_id") REFERENCES "tournaments" ("id") ON DELETE CASCADE`).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateIndex().
+		IfNotExists().
+		Model((*tournamentMatch)(nil)).
+		Index("idx_tournament_matches_tournament_id").
+		Column("tournament_id").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateIndex().
+		IfNotExists().
+		Model((*tournamentMatch)(nil)).
+		Index("idx_tournament_matches_game_id").
+		Column("game_id").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type tournament struct {
+	bun.BaseModel `bun:"table:tournaments"`
+
+	ID        uuid.UUID `bun:"type:uuid,pk"`
+	CreatedAt time.Time `bun:"created_at,notnull"`
+	UpdatedAt time.Time `bun:"updated_at,notnull"`
+
+	Entries []*tournamentEntry `bun:"rel:has-many,join:id=tournament_id"`
+	Matches []*tournamentMatch `bun:"rel:has-many,join:id=tournament_id"`
+
+	Creator              string          `bun:"type:uuid,notnull"`
+	BuyIn                uint64          `bun:"buy_in,notnull"`
+	MaxPlayers           uint8           `bun:"max_players,notnull"`
+	RegistrationDeadline time.Time       `bun:"registration_deadline,notnull"`
+	Prizes               []float64       `bun:"prizes,type:jsonb,notnull"`
+	Round                uint8           `bun:"round,notnull"`
+	State                TournamentState `bun:"state,notnull"`
+}
+
+// tournamentEntry hold buy-in of the player until tournament settled, after that prize is profit of the player.
+type tournamentEntry struct {
+	bun.BaseModel `bun:"table:tournament_entries"`
+
+	TournamentID uuid.UUID `bun:"type:uuid,pk"`
+	AccountID    uuid.UUID `bun:"type:uuid,pk"`
+	CreatedAt    time.Time `bun:"created_at,notnull"`
+
+	BuyIn   uint64 `bun:"buy_in,notnull"`
+	Place   uint8  `bun:"place,notnull"`
+	Prize   int64  `bun:"prize,notnull"`
+	Settled bool   `bun:"settled,notnull"`
+}
+
+type tournamentMatch struct {
+	bun.BaseModel `bun:"table:tournament_matches"`
+
+	ID int64 `bun:"id,pk,autoincrement"`
+
+	TournamentID uuid.UUID `bun:"type:uuid,notnull"`
+	Round        uint8     `bun:"round,notnull"`
+	Slot         uint8     `bun:"slot,notnull"`
+	GameID       uuid.UUID `bun:"type:uuid,nullzero"`
+	PlayerA      uuid.UUID `bun:"type:uuid,notnull"`
+	PlayerB      uuid.UUID `bun:"type:uuid,notnull"`
+	Winner       uuid.UUID `bun:"type:uuid,nullzero"`
 }
 
 func (g *gameDb) createLockTable(ctx context.Context) error {
@@ -214,6 +458,32 @@ func (g *gameDb) createLockTable(ctx context.Context) error {
 		return err
 	}
 
+	// matchmaking hold stake of queued player without game
+	_, err = g.db.NewRaw(`ALTER TABLE "locks" ALTER COLUMN "game_id" DROP NOT NULL`).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewAddColumn().
+		IfNotExists().
+		Model((*lock)(nil)).
+		ColumnExpr("ticket_id uuid").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = g.db.NewCreateIndex().
+		IfNotExists().
+		Model((*lock)(nil)).
+		Index("idx_locks_ticket_id").
+		Column("ticket_id").
+		Unique().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -222,9 +492,11 @@ type lock struct {
 
 	ID int64 `bun:"id,pk,autoincrement"`
 
-	GameID    uuid.UUID `bun:"type:uuid,notnull"`
+	GameID    uuid.UUID `bun:"type:uuid,nullzero"`
 	AccountID uuid.UUID `bun:"type:uuid,notnull"`
 	Amount    uint64    `bun:"amount,notnull"`
+	// TicketID set instead of GameID while player wait in matchmaking queue
+	TicketID uuid.UUID `bun:"type:uuid,nullzero"`
 }
 
 func (g *gameDb) createTransactionsTable(ctx context.Context) error {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/PxyUp/ton_games_example/pkg/activity"
 	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/google/uuid"
 	"github.com/uptrace/bun"
@@ -162,7 +163,14 @@ func (g *gameDb) UpdateOutTxByID(ctx context.Context, ID []byte, newID []byte, l
 		return nil, g.hideError(errTx)
 	}
 
-	return g.GetTxById(ctx, newID)
+	rec, err := g.GetTxById(ctx, newID)
+	if err != nil {
+		return nil, err
+	}
+
+	g.publish(txEvent(activity.WithdrawalFinished, rec))
+
+	return rec, nil
 }
 
 func (g *gameDb) StorePendingOutTx(ctx context.Context, txx TransactionRecordStore, cb func() error) (TransactionRecord, error) {
@@ -207,10 +215,19 @@ func (g *gameDb) StorePendingOutTx(ctx context.Context, txx TransactionRecordSto
 		return nil, g.hideError(errTx)
 	}
 
-	return g.GetTxById(ctx, txx.GetID())
+	rec, err := g.GetTxById(ctx, txx.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	g.publish(txEvent(activity.WithdrawalPending, rec))
+
+	return rec, nil
 }
 
+// storeTx store transaction once, deposit or withdrawal event published only for new transaction.
 func (g *gameDb) storeTx(ctx context.Context, txx *transaction, lastTxs uint64) (TransactionRecord, error) {
+	inserted := false
 	errTx := g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
 		count, errCount := tx.NewSelect().Model((*transaction)(nil)).Where("id = ?", txx.ID).Count(ctx)
 		if errCount != nil {
@@ -225,6 +242,7 @@ func (g *gameDb) storeTx(ctx context.Context, txx *transaction, lastTxs uint64)
 		if errCreate != nil {
 			return errCreate
 		}
+		inserted = true
 
 		timeNow := time.Now()
 		_, errUpdate := tx.NewInsert().On("CONFLICT (id) DO UPDATE").Set("updated_at = ?", time.Now()).Set("last_tx = ?", lastTxs).Model(&setting{
@@ -239,7 +257,18 @@ func (g *gameDb) storeTx(ctx context.Context, txx *transaction, lastTxs uint64)
 		return nil, g.hideError(errTx)
 	}
 
-	return g.GetTxById(ctx, txx.ID)
+	rec, err := g.GetTxById(ctx, txx.ID)
+	if err != nil || !inserted {
+		return rec, err
+	}
+
+	kind := activity.Deposit
+	if txx.Type == Out {
+		kind = activity.WithdrawalFinished
+	}
+	g.publish(txEvent(kind, rec))
+
+	return rec, nil
 }
 
 func (g *gameDb) StoreOutTx(ctx context.Context, tx TransactionRecordStore, lastTxs uint64) (TransactionRecord, error) {
@@ -296,6 +325,8 @@ type pureBalance struct {
 	Hold              int64
 	TotalWins         int64
 	TotalBonuses      int64
+	TournamentHold    int64
+	TournamentPrizes  int64
 }
 
 func (g *gameDb) GetBalanceByPlayerID(ctx context.Context, ID uuid.UUID) (BalanceRecord, error) {
@@ -308,35 +339,86 @@ func (g *gameDb) GetBalanceByPlayerID(ctx context.Context, ID uuid.UUID) (Balanc
 
 	var errGroup errgroup.Group
 	bp := &pureBalance{}
-
-	errGroup.Go(func() error {
-		return g.db.NewSelect().Model((*transaction)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("address = ?", acc.Address).Where("state = ?", Finished).Scan(ctx, &bp.Total)
-	})
-	errGroup.Go(func() error {
-		return g.db.NewSelect().Model((*transaction)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("address = ?", acc.Address).Where("state = ?", Pending).Where("type = ?", Out).Scan(ctx, &bp.PendingWithdrawal)
-	})
-	errGroup.Go(func() error {
-		return g.db.NewSelect().Model((*lock)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.Hold)
-	})
-	errGroup.Go(func() error {
-		return g.db.NewSelect().Model((*win)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.TotalWins)
-	})
-	errGroup.Go(func() error {
-		return g.db.NewSelect().Model((*bonus)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.TotalBonuses)
-	})
+	for _, query := range bp.queries(g.db, acc) {
+		query := query
+		errGroup.Go(func() error {
+			return query(ctx)
+		})
+	}
 	if err := errGroup.Wait(); err != nil {
 		return nil, g.hideError(err)
 	}
 
-	totalWithPending := bp.Total + bp.TotalBonuses - (-bp.PendingWithdrawal) + bp.TotalWins
-	if totalWithPending < bp.Hold {
+	record, err := bp.record()
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// lockBalance lock row of the account until end of transaction and return balance, so concurrent stakes of the account
+// checked one by one against balance which already include previous ones.
+func lockBalance(ctx context.Context, tx bun.Tx, ID uuid.UUID) (*balanceRecord, error) {
+	acc := &account{}
+
+	errPlayer := tx.NewSelect().Model(acc).Column("id", "address").Where("id = ?", ID).For("UPDATE").Scan(ctx)
+	if errPlayer != nil {
+		return nil, errPlayer
+	}
+
+	bp := &pureBalance{}
+	// transaction can't run queries in parallel
+	for _, query := range bp.queries(tx, acc) {
+		errQuery := query(ctx)
+		if errQuery != nil {
+			return nil, errQuery
+		}
+	}
+
+	return bp.record()
+}
+
+// queries return queries which fill parts of the balance of the account.
+func (bp *pureBalance) queries(db bun.IDB, acc *account) []func(ctx context.Context) error {
+	return []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*transaction)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("address = ?", acc.Address).Where("state = ?", Finished).Scan(ctx, &bp.Total)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*transaction)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("address = ?", acc.Address).Where("state = ?", Pending).Where("type = ?", Out).Scan(ctx, &bp.PendingWithdrawal)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*lock)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.Hold)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*win)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.TotalWins)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*bonus)(nil)).ColumnExpr("coalesce(SUM(amount), 0)").Where("account_id = ?", acc.ID).Scan(ctx, &bp.TotalBonuses)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*tournamentEntry)(nil)).ColumnExpr("coalesce(SUM(buy_in), 0)").Where("account_id = ?", acc.ID).Where("settled = ?", false).Scan(ctx, &bp.TournamentHold)
+		},
+		func(ctx context.Context) error {
+			return db.NewSelect().Model((*tournamentEntry)(nil)).ColumnExpr("coalesce(SUM(prize), 0)").Where("account_id = ?", acc.ID).Where("settled = ?", true).Scan(ctx, &bp.TournamentPrizes)
+		},
+	}
+}
+
+func (bp *pureBalance) record() (*balanceRecord, error) {
+	profit := bp.TotalWins + bp.TournamentPrizes
+	hold := bp.Hold + bp.TournamentHold
+
+	totalWithPending := bp.Total + bp.TotalBonuses - (-bp.PendingWithdrawal) + profit
+	if totalWithPending < hold {
 		return nil, ErrInternalDBError
 	}
 
 	return &balanceRecord{
-		available:         uint64(totalWithPending - bp.Hold),
-		hold:              uint64(bp.Hold),
+		available:         uint64(totalWithPending - hold),
+		hold:              uint64(hold),
 		pendingWithdrawal: uint64(-1 * bp.PendingWithdrawal),
-		profit:            bp.TotalWins,
+		profit:            profit,
 	}, nil
 }
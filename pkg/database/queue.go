@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PxyUp/ton_games_example/pkg/activity"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var (
+	_ QueueDB = &gameDb{}
+
+	ErrHoldNotFound = errors.New("hold of the ticket not found")
+)
+
+type QueueDB interface {
+	// HoldStake lock stake of the player while ticket in matchmaking queue.
+	HoldStake(ctx context.Context, ticketId string, playerID string, stake float64) error
+	// ReleaseStake unlock stake of the ticket.
+	ReleaseStake(ctx context.Context, ticketId string) error
+}
+
+func (g *gameDb) HoldStake(ctx context.Context, ticketId string, playerID string, stake float64) error {
+	ticketIdUuid, err := uuid.Parse(ticketId)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	playerIDUuid, err := uuid.Parse(playerID)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	valid, cost, err := g.canPlayerJoinGame(ctx, stake, playerID)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	if !valid {
+		return ErrSmallBalance
+	}
+
+	held := &lock{
+		TicketID:  ticketIdUuid,
+		AccountID: playerIDUuid,
+		Amount:    cost,
+	}
+	_, errLock := g.db.NewInsert().Model(held).Exec(ctx)
+	if errLock != nil {
+		return g.hideError(errLock)
+	}
+
+	g.publish(lockEvent(activity.Lock, held))
+
+	return nil
+}
+
+func (g *gameDb) ReleaseStake(ctx context.Context, ticketId string) error {
+	ticketIdUuid, err := uuid.Parse(ticketId)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	released := []*lock{}
+	_, errDelete := g.db.NewDelete().Model((*lock)(nil)).Where("ticket_id = ?", ticketIdUuid).Returning("*").Exec(ctx, &released)
+	if errDelete != nil {
+		return g.hideError(errDelete)
+	}
+
+	g.publish(unlockEvents(released)...)
+
+	return nil
+}
+
+// seatTickets replace holds of the tickets by locks of the game, first ticket is hold of the creator.
+// House is account which play against players (uuid.Nil if game not against house), it can't take a seat.
+// Return released holds and created locks.
+func seatTickets(ctx context.Context, tx bun.Tx, gr *game, cost uint64, house uuid.UUID) ([]*lock, []*lock, error) {
+	released := make([]*lock, 0, len(gr.tickets))
+	locked := make([]*lock, 0, len(gr.tickets))
+	for i, ticketId := range gr.tickets {
+		ticketIdUuid, err := uuid.Parse(ticketId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		holds := []*lock{}
+		_, errDelete := tx.NewDelete().Model((*lock)(nil)).Where("ticket_id = ?", ticketIdUuid).Returning("*").Exec(ctx, &holds)
+		if errDelete != nil {
+			return nil, nil, errDelete
+		}
+
+		if len(holds) != 1 || (i == 0 && holds[0].AccountID.String() != gr.Creator) {
+			return nil, nil, ErrHoldNotFound
+		}
+
+		if holds[0].AccountID == house {
+			return nil, nil, ErrHouseCantPlay
+		}
+
+		if holds[0].Amount < cost {
+			return nil, nil, ErrSmallBalance
+		}
+
+		stake := &lock{
+			GameID:    gr.ID,
+			AccountID: holds[0].AccountID,
+			Amount:    cost,
+		}
+		_, errLock := tx.NewInsert().Model(stake).Exec(ctx)
+		if errLock != nil {
+			return nil, nil, errLock
+		}
+
+		_, errGameAccount := tx.NewInsert().Model(&accountGame{
+			GameID:    gr.ID,
+			AccountID: holds[0].AccountID,
+		}).Exec(ctx)
+		if errGameAccount != nil {
+			return nil, nil, errGameAccount
+		}
+
+		if i > 0 && gr.join != nil {
+			errJoin := gr.join(holds[0].AccountID.String())
+			if errJoin != nil {
+				return nil, nil, errJoin
+			}
+		}
+
+		released = append(released, holds[0])
+		locked = append(locked, stake)
+	}
+
+	return released, locked, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var (
+	_ SnapshotDB = &gameDb{}
+)
+
+type SnapshotDB interface {
+	StoreSnapshot(ctx context.Context, snapshot *games.Snapshot) error
+	DeleteSnapshot(ctx context.Context, gameId string) error
+	// GetSnapshots return snapshots of created and in progress games.
+	GetSnapshots(ctx context.Context) ([]*games.Snapshot, error)
+	// DiscardSnapshot finish game which can not be restored and unlock all players.
+	DiscardSnapshot(ctx context.Context, gameId string) error
+}
+
+func (g *gameDb) StoreSnapshot(ctx context.Context, gameSnapshot *games.Snapshot) error {
+	gameIdUuid, err := uuid.Parse(gameSnapshot.GameID)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	_, errInsert := g.db.NewInsert().Model(&snapshot{
+		GameID:    gameIdUuid,
+		UpdatedAt: time.Now(),
+		Type:      gameSnapshot.GameType,
+		Creator:   gameSnapshot.Creator,
+		Deadline:  gameSnapshot.Deadline,
+		State:     gameSnapshot.State,
+	}).On("CONFLICT (game_id) DO UPDATE").
+		Set("updated_at = EXCLUDED.updated_at").
+		Set("deadline = EXCLUDED.deadline").
+		Set("state = EXCLUDED.state").
+		Exec(ctx)
+	if errInsert != nil {
+		return g.hideError(errInsert)
+	}
+
+	return nil
+}
+
+func (g *gameDb) DeleteSnapshot(ctx context.Context, gameId string) error {
+	gameIdUuid, err := uuid.Parse(gameId)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	_, errDelete := g.db.NewDelete().Model((*snapshot)(nil)).Where("game_id = ?", gameIdUuid).Exec(ctx)
+	if errDelete != nil {
+		return g.hideError(errDelete)
+	}
+
+	return nil
+}
+
+func (g *gameDb) GetSnapshots(ctx context.Context) ([]*games.Snapshot, error) {
+	list := []*snapshot{}
+
+	errList := g.db.NewSelect().Model(&list).Where("game_id IN (?)", g.db.NewSelect().Model((*game)(nil)).Column("id").Where("state IN (?)", bun.In([]games.GameState{games.GameInProgress, games.GameCreated}))).Scan(ctx)
+	if errList != nil {
+		return nil, g.hideError(errList)
+	}
+
+	snapshots := make([]*games.Snapshot, len(list))
+	for i := range list {
+		snapshots[i] = &games.Snapshot{
+			GameID:   list[i].GameID.String(),
+			GameType: list[i].Type,
+			Creator:  list[i].Creator,
+			Deadline: list[i].Deadline,
+			State:    list[i].State,
+		}
+	}
+
+	return snapshots, nil
+}
+
+func (g *gameDb) DiscardSnapshot(ctx context.Context, gameId string) error {
+	gameIdUuid, err := uuid.Parse(gameId)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, errUpdate := tx.NewUpdate().Model((*game)(nil)).Set("state = ?", games.GameFinished).Set("updated_at = ?", time.Now()).Where("id = ?", gameIdUuid).Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		_, errLock := tx.NewDelete().Model((*lock)(nil)).Where("game_id = ?", gameIdUuid).Exec(ctx)
+		if errLock != nil {
+			return errLock
+		}
+
+		_, errSnapshot := tx.NewDelete().Model((*snapshot)(nil)).Where("game_id = ?", gameIdUuid).Exec(ctx)
+		if errSnapshot != nil {
+			return errSnapshot
+		}
+
+		return nil
+	})
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	return nil
+}
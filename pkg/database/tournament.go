@@ -0,0 +1,521 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+var (
+	_ TournamentDB = &gameDb{}
+)
+
+var (
+	ErrTournamentClosed             = errors.New("tournament registration closed")
+	ErrTournamentFull               = errors.New("tournament is full")
+	ErrPlayerAlreadyInTournament    = errors.New("player already registered in tournament")
+	ErrPlayerNotInTournament        = errors.New("player not registered in tournament")
+	ErrCreatorCantLeftTournament    = errors.New("creator cant left tournament")
+	ErrTournamentMatchNotFound      = errors.New("tournament match not found")
+	ErrTournamentMatchFinished      = errors.New("tournament match already finished")
+	ErrTournamentMatchInvalidPlayer = errors.New("player not part of tournament match")
+	ErrTournamentRoundStarted       = errors.New("tournament round already started or tournament closed")
+)
+
+// TournamentSettings used for creation of the tournament.
+type TournamentSettings struct {
+	BuyIn                float64
+	MaxPlayers           uint8
+	RegistrationDeadline time.Time
+	Prizes               []float64
+}
+
+type TournamentDB interface {
+	// CreateTournament create tournament in registration state, creator registered with buy-in.
+	CreateTournament(ctx context.Context, creator string, settings *TournamentSettings) (TournamentRecord, error)
+	GetTournamentById(ctx context.Context, tournamentId string) (TournamentRecord, error)
+	GetTournaments(ctx context.Context, states ...TournamentState) ([]TournamentRecord, error)
+	// GetTournamentByGame return tournament and match played by the game, ErrTournamentMatchNotFound for other games.
+	GetTournamentByGame(ctx context.Context, gameId string) (TournamentRecord, TournamentMatchRecord, error)
+	RegisterInTournament(ctx context.Context, tournamentId string, playerID string) (TournamentRecord, error)
+	LeftTournament(ctx context.Context, tournamentId string, playerID string) (TournamentRecord, error)
+	// CancelTournament cancel tournament in registration state, buy-ins returned to players.
+	CancelTournament(ctx context.Context, tournamentId string) (TournamentRecord, error)
+	// StartTournamentRound move tournament to the round and create matches for pairs of players.
+	// First round started only from registration, next one only right after previous round, otherwise ErrTournamentRoundStarted.
+	StartTournamentRound(ctx context.Context, tournamentId string, round uint8, pairs [][2]string) (TournamentRecord, error)
+	SetTournamentMatchGame(ctx context.Context, matchID int64, gameId string) error
+	// FinishTournamentMatch store winner of the match, loser leave tournament with loserPlace.
+	FinishTournamentMatch(ctx context.Context, matchID int64, winner string, loserPlace uint8) (TournamentRecord, error)
+	// FinishTournament settle all entries, prizes in nano by player id, player without place take first one.
+	FinishTournament(ctx context.Context, tournamentId string, prizes map[string]uint64) (TournamentRecord, error)
+}
+
+func (g *gameDb) hideTournamentError(err error) error {
+	for _, known := range []error{
+		ErrTournamentClosed,
+		ErrTournamentFull,
+		ErrPlayerAlreadyInTournament,
+		ErrPlayerNotInTournament,
+		ErrCreatorCantLeftTournament,
+		ErrTournamentMatchNotFound,
+		ErrTournamentMatchFinished,
+		ErrTournamentMatchInvalidPlayer,
+		ErrTournamentRoundStarted,
+		ErrSmallBalance,
+	} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+
+	return g.hideError(err)
+}
+
+// canPlayerPayBuyIn check available balance of the player for buy-in in nano.
+func (g *gameDb) canPlayerPayBuyIn(ctx context.Context, buyIn uint64, playerID uuid.UUID) error {
+	balance, err := g.GetBalanceByPlayerID(ctx, playerID)
+	if err != nil {
+		return err
+	}
+
+	if balance.Available() < buyIn {
+		return ErrSmallBalance
+	}
+
+	return nil
+}
+
+func (g *gameDb) CreateTournament(ctx context.Context, creator string, settings *TournamentSettings) (TournamentRecord, error) {
+	creatorIDUuid, err := uuid.Parse(creator)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	buyIn := uint64(time.Duration(settings.BuyIn * float64(time.Second)))
+
+	err = g.canPlayerPayBuyIn(ctx, buyIn, creatorIDUuid)
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	timeNow := time.Now()
+	tr := &tournament{
+		ID:                   uuid.New(),
+		CreatedAt:            timeNow,
+		UpdatedAt:            timeNow,
+		Creator:              creator,
+		BuyIn:                buyIn,
+		MaxPlayers:           settings.MaxPlayers,
+		RegistrationDeadline: settings.RegistrationDeadline,
+		Prizes:               settings.Prizes,
+		State:                TournamentRegistration,
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		_, errCreated := tx.NewInsert().Model(tr).Exec(ctx)
+		if errCreated != nil {
+			return errCreated
+		}
+
+		_, errEntry := tx.NewInsert().Model(&tournamentEntry{
+			TournamentID: tr.ID,
+			AccountID:    creatorIDUuid,
+			CreatedAt:    timeNow,
+			BuyIn:        buyIn,
+		}).Exec(ctx)
+		if errEntry != nil {
+			return errEntry
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	return g.GetTournamentById(ctx, tr.ID.String())
+}
+
+func (g *gameDb) GetTournamentById(ctx context.Context, tournamentId string) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	tr := &tournament{}
+	errScan := g.db.NewSelect().Model(tr).Where("id = ?", tournamentIdUuid).Relation("Entries").Relation("Matches").Scan(ctx)
+	if errScan != nil {
+		return nil, g.hideError(errScan)
+	}
+
+	return tournamentFromDao(tr), nil
+}
+
+func (g *gameDb) GetTournaments(ctx context.Context, states ...TournamentState) ([]TournamentRecord, error) {
+	list := []*tournament{}
+
+	errList := g.db.NewSelect().Model(&list).Where("state IN (?)", bun.In(states)).Order("created_at desc").Relation("Entries").Relation("Matches").Scan(ctx)
+	if errList != nil {
+		return nil, g.hideError(errList)
+	}
+
+	records := make([]TournamentRecord, len(list))
+	for i := range list {
+		records[i] = tournamentFromDao(list[i])
+	}
+
+	return records, nil
+}
+
+func (g *gameDb) GetTournamentByGame(ctx context.Context, gameId string) (TournamentRecord, TournamentMatchRecord, error) {
+	gameIdUuid, err := uuid.Parse(gameId)
+	if err != nil {
+		return nil, nil, g.hideError(err)
+	}
+
+	match := &tournamentMatch{}
+	errScan := g.db.NewSelect().Model(match).Where("game_id = ?", gameIdUuid).Scan(ctx)
+	if errors.Is(errScan, sql.ErrNoRows) {
+		return nil, nil, ErrTournamentMatchNotFound
+	}
+	if errScan != nil {
+		return nil, nil, g.hideError(errScan)
+	}
+
+	tr, err := g.GetTournamentById(ctx, match.TournamentID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tr, matchFromDao(match), nil
+}
+
+func (g *gameDb) RegisterInTournament(ctx context.Context, tournamentId string, playerID string) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	playerIDUuid, err := uuid.Parse(playerID)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	current := &tournament{}
+	err = g.db.NewSelect().Model(current).Column("buy_in").Where("id = ?", tournamentIdUuid).Scan(ctx)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	err = g.canPlayerPayBuyIn(ctx, current.BuyIn, playerIDUuid)
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		tr := &tournament{}
+		errSelect := tx.NewSelect().Model(tr).Where("id = ?", tournamentIdUuid).For("UPDATE").Scan(ctx)
+		if errSelect != nil {
+			return errSelect
+		}
+
+		if tr.State != TournamentRegistration || time.Now().After(tr.RegistrationDeadline) {
+			return ErrTournamentClosed
+		}
+
+		registered, errRegistered := tx.NewSelect().Model((*tournamentEntry)(nil)).Where("tournament_id = ?", tournamentIdUuid).Where("account_id = ?", playerIDUuid).Exists(ctx)
+		if errRegistered != nil {
+			return errRegistered
+		}
+
+		if registered {
+			return ErrPlayerAlreadyInTournament
+		}
+
+		count, errCount := tx.NewSelect().Model((*tournamentEntry)(nil)).Where("tournament_id = ?", tournamentIdUuid).Count(ctx)
+		if errCount != nil {
+			return errCount
+		}
+
+		if count >= int(tr.MaxPlayers) {
+			return ErrTournamentFull
+		}
+
+		_, errEntry := tx.NewInsert().Model(&tournamentEntry{
+			TournamentID: tournamentIdUuid,
+			AccountID:    playerIDUuid,
+			CreatedAt:    time.Now(),
+			BuyIn:        tr.BuyIn,
+		}).Exec(ctx)
+		if errEntry != nil {
+			return errEntry
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	return g.GetTournamentById(ctx, tournamentId)
+}
+
+func (g *gameDb) LeftTournament(ctx context.Context, tournamentId string, playerID string) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	playerIDUuid, err := uuid.Parse(playerID)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		tr := &tournament{}
+		errSelect := tx.NewSelect().Model(tr).Where("id = ?", tournamentIdUuid).For("UPDATE").Scan(ctx)
+		if errSelect != nil {
+			return errSelect
+		}
+
+		if tr.State != TournamentRegistration {
+			return ErrTournamentClosed
+		}
+
+		if tr.Creator == playerID {
+			return ErrCreatorCantLeftTournament
+		}
+
+		res, errDelete := tx.NewDelete().Model((*tournamentEntry)(nil)).Where("tournament_id = ?", tournamentIdUuid).Where("account_id = ?", playerIDUuid).Exec(ctx)
+		if errDelete != nil {
+			return errDelete
+		}
+
+		affected, errAffected := res.RowsAffected()
+		if errAffected != nil {
+			return errAffected
+		}
+
+		if affected == 0 {
+			return ErrPlayerNotInTournament
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	return g.GetTournamentById(ctx, tournamentId)
+}
+
+func (g *gameDb) CancelTournament(ctx context.Context, tournamentId string) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		res, errUpdate := tx.NewUpdate().Model((*tournament)(nil)).Set("state = ?", TournamentCanceled).Set("updated_at = ?", time.Now()).Where("id = ?", tournamentIdUuid).Where("state = ?", TournamentRegistration).Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		affected, errAffected := res.RowsAffected()
+		if errAffected != nil {
+			return errAffected
+		}
+
+		if affected == 0 {
+			return ErrTournamentClosed
+		}
+
+		_, errEntries := tx.NewUpdate().Model((*tournamentEntry)(nil)).Set("settled = ?", true).Set("prize = ?", 0).Where("tournament_id = ?", tournamentIdUuid).Exec(ctx)
+		if errEntries != nil {
+			return errEntries
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	return g.GetTournamentById(ctx, tournamentId)
+}
+
+func (g *gameDb) StartTournamentRound(ctx context.Context, tournamentId string, round uint8, pairs [][2]string) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	matches := make([]*tournamentMatch, len(pairs))
+	for i, pair := range pairs {
+		playerA, errA := uuid.Parse(pair[0])
+		if errA != nil {
+			return nil, g.hideError(errA)
+		}
+
+		playerB, errB := uuid.Parse(pair[1])
+		if errB != nil {
+			return nil, g.hideError(errB)
+		}
+
+		matches[i] = &tournamentMatch{
+			TournamentID: tournamentIdUuid,
+			Round:        round,
+			Slot:         uint8(i),
+			PlayerA:      playerA,
+			PlayerB:      playerB,
+		}
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		query := tx.NewUpdate().Model((*tournament)(nil)).Set("state = ?", TournamentRunning).Set("round = ?", round).Set("updated_at = ?", time.Now()).Where("id = ?", tournamentIdUuid)
+		if round == 1 {
+			query = query.Where("state = ?", TournamentRegistration)
+		} else {
+			query = query.Where("state = ?", TournamentRunning).Where("round = ?", round-1)
+		}
+
+		res, errUpdate := query.Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		affected, errAffected := res.RowsAffected()
+		if errAffected != nil {
+			return errAffected
+		}
+
+		if affected == 0 {
+			return ErrTournamentRoundStarted
+		}
+
+		if len(matches) == 0 {
+			return nil
+		}
+
+		_, errInsert := tx.NewInsert().Model(&matches).Exec(ctx)
+		if errInsert != nil {
+			return errInsert
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	return g.GetTournamentById(ctx, tournamentId)
+}
+
+func (g *gameDb) SetTournamentMatchGame(ctx context.Context, matchID int64, gameId string) error {
+	gameIdUuid, err := uuid.Parse(gameId)
+	if err != nil {
+		return g.hideError(err)
+	}
+
+	_, errUpdate := g.db.NewUpdate().Model((*tournamentMatch)(nil)).Set("game_id = ?", gameIdUuid).Where("id = ?", matchID).Exec(ctx)
+	if errUpdate != nil {
+		return g.hideError(errUpdate)
+	}
+
+	return nil
+}
+
+func (g *gameDb) FinishTournamentMatch(ctx context.Context, matchID int64, winner string, loserPlace uint8) (TournamentRecord, error) {
+	winnerUuid, err := uuid.Parse(winner)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	match := &tournamentMatch{}
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		errSelect := tx.NewSelect().Model(match).Where("id = ?", matchID).For("UPDATE").Scan(ctx)
+		if errSelect != nil {
+			return errSelect
+		}
+
+		if match.Winner != uuid.Nil {
+			return ErrTournamentMatchFinished
+		}
+
+		loser := match.PlayerA
+		switch winnerUuid {
+		case match.PlayerA:
+			loser = match.PlayerB
+		case match.PlayerB:
+		default:
+			return ErrTournamentMatchInvalidPlayer
+		}
+
+		_, errUpdate := tx.NewUpdate().Model((*tournamentMatch)(nil)).Set("winner = ?", winnerUuid).Where("id = ?", matchID).Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		_, errPlace := tx.NewUpdate().Model((*tournamentEntry)(nil)).Set("place = ?", loserPlace).Where("tournament_id = ?", match.TournamentID).Where("account_id = ?", loser).Exec(ctx)
+		if errPlace != nil {
+			return errPlace
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideTournamentError(err)
+	}
+
+	return g.GetTournamentById(ctx, match.TournamentID.String())
+}
+
+func (g *gameDb) FinishTournament(ctx context.Context, tournamentId string, prizes map[string]uint64) (TournamentRecord, error) {
+	tournamentIdUuid, err := uuid.Parse(tournamentId)
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	err = g.db.RunInTx(ctx, &sql.TxOptions{}, func(ctx context.Context, tx bun.Tx) error {
+		entries := []*tournamentEntry{}
+		errEntries := tx.NewSelect().Model(&entries).Where("tournament_id = ?", tournamentIdUuid).Where("settled = ?", false).Scan(ctx)
+		if errEntries != nil {
+			return errEntries
+		}
+
+		for _, entry := range entries {
+			place := entry.Place
+			if place == 0 {
+				place = 1
+			}
+
+			_, errUpdate := tx.NewUpdate().Model((*tournamentEntry)(nil)).
+				Set("place = ?", place).
+				Set("prize = ?", int64(prizes[entry.AccountID.String()])-int64(entry.BuyIn)).
+				Set("settled = ?", true).
+				Where("tournament_id = ?", tournamentIdUuid).
+				Where("account_id = ?", entry.AccountID).
+				Exec(ctx)
+			if errUpdate != nil {
+				return errUpdate
+			}
+		}
+
+		_, errUpdate := tx.NewUpdate().Model((*tournament)(nil)).Set("state = ?", TournamentFinished).Set("updated_at = ?", time.Now()).Where("id = ?", tournamentIdUuid).Exec(ctx)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, g.hideError(err)
+	}
+
+	return g.GetTournamentById(ctx, tournamentId)
+}
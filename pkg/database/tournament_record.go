@@ -0,0 +1,253 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xssnick/tonutils-go/tlb"
+)
+
+var (
+	_ TournamentRecord      = &tournamentRecord{}
+	_ TournamentMatchRecord = &tournamentMatchRecord{}
+)
+
+type TournamentRecord interface {
+	GetId() string
+	GetCreator() string
+	GetBuyIn() int64
+	GetMaxPlayers() uint8
+	GetState() TournamentState
+	GetRound() uint8
+	GetPrizes() []float64
+	GetRegistrationDeadline() time.Time
+	GetPlayers() []string
+	GetMatches() []TournamentMatchRecord
+	JSON() map[string]interface{}
+}
+
+type TournamentMatchRecord interface {
+	GetId() int64
+	GetRound() uint8
+	GetSlot() uint8
+	GetGameId() string
+	GetPlayers() []string
+	GetWinner() string
+	JSON() map[string]interface{}
+}
+
+type tournamentEntryRecord struct {
+	Player string `json:"player"`
+	Place  uint8  `json:"place"`
+	Prize  string `json:"prize"`
+}
+
+type tournamentRecord struct {
+	id                   string
+	creator              string
+	buyIn                int64
+	maxPlayers           uint8
+	state                TournamentState
+	round                uint8
+	prizes               []float64
+	registrationDeadline time.Time
+	creationTime         time.Time
+
+	players []string
+	entries []*tournamentEntryRecord
+	matches []TournamentMatchRecord
+}
+
+func (t *tournamentRecord) GetId() string {
+	return t.id
+}
+
+func (t *tournamentRecord) GetCreator() string {
+	return t.creator
+}
+
+func (t *tournamentRecord) GetBuyIn() int64 {
+	return t.buyIn
+}
+
+func (t *tournamentRecord) GetMaxPlayers() uint8 {
+	return t.maxPlayers
+}
+
+func (t *tournamentRecord) GetState() TournamentState {
+	return t.state
+}
+
+func (t *tournamentRecord) GetRound() uint8 {
+	return t.round
+}
+
+func (t *tournamentRecord) GetPrizes() []float64 {
+	return t.prizes
+}
+
+func (t *tournamentRecord) GetRegistrationDeadline() time.Time {
+	return t.registrationDeadline
+}
+
+func (t *tournamentRecord) GetPlayers() []string {
+	return t.players
+}
+
+func (t *tournamentRecord) GetMatches() []TournamentMatchRecord {
+	return t.matches
+}
+
+func (t *tournamentRecord) JSON() map[string]interface{} {
+	timeLeft := 0
+	if t.GetState() == TournamentRegistration {
+		timeLeft = int(time.Until(t.GetRegistrationDeadline()).Seconds())
+		if timeLeft < 0 {
+			timeLeft = 0
+		}
+	}
+
+	matches := make([]map[string]interface{}, len(t.matches))
+	for i, match := range t.matches {
+		matches[i] = match.JSON()
+	}
+
+	return map[string]interface{}{
+		"id":                     t.GetId(),
+		"creation_time":          t.creationTime,
+		"registration_time_left": timeLeft,
+		"buy_in":                 tlb.FromNanoTONU(uint64(t.buyIn)).String(),
+		"prize_pool":             tlb.FromNanoTONU(uint64(t.buyIn) * uint64(len(t.players))).String(),
+		"prizes":                 t.GetPrizes(),
+		"players":                t.GetPlayers(),
+		"entries":                t.entries,
+		"max_players":            t.GetMaxPlayers(),
+		"creator":                t.GetCreator(),
+		"state":                  t.GetState(),
+		"round":                  t.GetRound(),
+		"matches":                matches,
+	}
+}
+
+type tournamentMatchRecord struct {
+	id      int64
+	round   uint8
+	slot    uint8
+	gameId  string
+	players []string
+	winner  string
+}
+
+func (m *tournamentMatchRecord) GetId() int64 {
+	return m.id
+}
+
+func (m *tournamentMatchRecord) GetRound() uint8 {
+	return m.round
+}
+
+func (m *tournamentMatchRecord) GetSlot() uint8 {
+	return m.slot
+}
+
+func (m *tournamentMatchRecord) GetGameId() string {
+	return m.gameId
+}
+
+func (m *tournamentMatchRecord) GetPlayers() []string {
+	return m.players
+}
+
+func (m *tournamentMatchRecord) GetWinner() string {
+	return m.winner
+}
+
+func (m *tournamentMatchRecord) JSON() map[string]interface{} {
+	return map[string]interface{}{
+		"id":      m.GetId(),
+		"round":   m.GetRound(),
+		"slot":    m.GetSlot(),
+		"game_id": m.GetGameId(),
+		"players": m.GetPlayers(),
+		"winner":  m.GetWinner(),
+	}
+}
+
+func nullableUUID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+func matchFromDao(dao *tournamentMatch) *tournamentMatchRecord {
+	return &tournamentMatchRecord{
+		id:      dao.ID,
+		round:   dao.Round,
+		slot:    dao.Slot,
+		gameId:  nullableUUID(dao.GameID),
+		players: []string{dao.PlayerA.String(), dao.PlayerB.String()},
+		winner:  nullableUUID(dao.Winner),
+	}
+}
+
+func tournamentFromDao(dao *tournament) *tournamentRecord {
+	entries := make([]*tournamentEntry, len(dao.Entries))
+	copy(entries, dao.Entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	players := make([]string, len(entries))
+	entryRecords := make([]*tournamentEntryRecord, len(entries))
+	for i, entry := range entries {
+		players[i] = entry.AccountID.String()
+
+		prize := ""
+		if entry.Settled {
+			if entry.Prize < 0 {
+				prize = fmt.Sprintf("- %s", tlb.FromNanoTONU(uint64(-entry.Prize)).String())
+			} else {
+				prize = tlb.FromNanoTONU(uint64(entry.Prize)).String()
+			}
+		}
+
+		entryRecords[i] = &tournamentEntryRecord{
+			Player: players[i],
+			Place:  entry.Place,
+			Prize:  prize,
+		}
+	}
+
+	matchList := make([]*tournamentMatch, len(dao.Matches))
+	copy(matchList, dao.Matches)
+	sort.Slice(matchList, func(i, j int) bool {
+		if matchList[i].Round != matchList[j].Round {
+			return matchList[i].Round < matchList[j].Round
+		}
+		return matchList[i].Slot < matchList[j].Slot
+	})
+
+	matches := make([]TournamentMatchRecord, len(matchList))
+	for i, match := range matchList {
+		matches[i] = matchFromDao(match)
+	}
+
+	return &tournamentRecord{
+		id:                   dao.ID.String(),
+		creator:              dao.Creator,
+		buyIn:                int64(dao.BuyIn),
+		maxPlayers:           dao.MaxPlayers,
+		state:                dao.State,
+		round:                dao.Round,
+		prizes:               dao.Prizes,
+		registrationDeadline: dao.RegistrationDeadline,
+		creationTime:         dao.CreatedAt,
+		players:              players,
+		entries:              entryRecords,
+		matches:              matches,
+	}
+}
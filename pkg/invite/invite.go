@@ -0,0 +1,75 @@
+package invite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid invite token")
+)
+
+// NewCode return short random invite code of private game.
+func NewCode(src random.Source) (string, error) {
+	code := make([]byte, 4)
+	_, err := src.Read(code)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(code), nil
+}
+
+func sign(secret string, gameID []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(gameID)
+	return h.Sum(nil)[:16]
+}
+
+// Token return signed token of the game, fits in telegram start parameter (64 chars of [A-Za-z0-9_-]).
+func Token(secret string, gameID string) (string, error) {
+	id, err := uuid.Parse(gameID)
+	if err != nil {
+		return "", err
+	}
+
+	payload := append(id[:], sign(secret, id[:])...)
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// Verify check token signature and return game id from it.
+func Verify(secret string, token string) (string, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(payload) != 32 {
+		return "", ErrInvalidToken
+	}
+
+	if subtle.ConstantTimeCompare(payload[16:], sign(secret, payload[:16])) != 1 {
+		return "", ErrInvalidToken
+	}
+
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	return id.String(), nil
+}
+
+// Link return invite link of the game, telegram mini app link used if configured.
+func Link(token string) string {
+	if config.Config.TelegramAppLink != "" {
+		return fmt.Sprintf("%s?startapp=%s", config.Config.TelegramAppLink, token)
+	}
+
+	return fmt.Sprintf("%s?invite=%s", config.Config.AppURL, token)
+}
@@ -0,0 +1,355 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/invite"
+	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/runtime"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Matchmaker = &matchmaker{}
+
+	ErrQuickPlayNotSupported = errors.New("game type not support quick play")
+	ErrInvalidStakeRange     = errors.New("invalid stake range")
+	ErrAlreadyQueued         = errors.New("player already in queue")
+	ErrTicketNotFound        = errors.New("ticket not found")
+	ErrTicketNotQueued       = errors.New("ticket not in queue")
+)
+
+type Matchmaker interface {
+	// Enqueue hold max stake of the player and put ticket in queue of game type, game created when compatible players found.
+	Enqueue(ctx context.Context, gameType games.GameType, playerID string, req *QuickPlayRequest) (Ticket, error)
+	Cancel(ctx context.Context, ticketId string, playerID string) (Ticket, error)
+	GetTicket(ctx context.Context, ticketId string, playerID string) (Ticket, error)
+}
+
+type matchmaker struct {
+	ctx   context.Context
+	mutex sync.Mutex
+
+	queues map[games.GameType][]*ticket
+	// tickets by id, finished tickets kept for QUICK_PLAY_TIMEOUT
+	tickets map[string]*ticket
+	// active ticket of the player
+	active map[string]*ticket
+
+	store   database.DB
+	runtime runtime.Runtime
+	log     logger.Logger
+	clock   clock.Clock
+}
+
+// New create matchmaker which create games through the runtime.
+func New(ctx context.Context, store database.DB, rt runtime.Runtime, logger2 logger.Logger) Matchmaker {
+	return &matchmaker{
+		ctx:     ctx,
+		queues:  make(map[games.GameType][]*ticket),
+		tickets: make(map[string]*ticket),
+		active:  make(map[string]*ticket),
+		store:   store,
+		runtime: rt,
+		log:     logger2,
+		clock:   rt.Env().Clock,
+	}
+}
+
+func (m *matchmaker) Enqueue(ctx context.Context, gameType games.GameType, playerID string, req *QuickPlayRequest) (Ticket, error) {
+	factory, err := games.GetFactory(gameType)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := factory.(games.QuickPlay); !ok {
+		return nil, ErrQuickPlayNotSupported
+	}
+
+	limits := factory.Limits()
+	if req.MinStake > req.MaxStake {
+		return nil, ErrInvalidStakeRange
+	}
+
+	errLimits := limits.Validate(req.MinStake, config.QUICK_PLAY_PLAYERS, limits.MinDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	errLimits = limits.Validate(req.MaxStake, config.QUICK_PLAY_PLAYERS, limits.MinDuration)
+	if errLimits != nil {
+		return nil, errLimits
+	}
+
+	t := &ticket{
+		id:        uuid.New().String(),
+		playerID:  playerID,
+		gameType:  gameType,
+		minStake:  req.MinStake,
+		maxStake:  req.MaxStake,
+		createdAt: m.clock.Now(),
+		state:     Queued,
+		done:      make(chan struct{}),
+	}
+
+	m.mutex.Lock()
+	if _, queued := m.active[playerID]; queued {
+		m.mutex.Unlock()
+		return nil, ErrAlreadyQueued
+	}
+	m.active[playerID] = t
+	m.mutex.Unlock()
+
+	err = m.store.HoldStake(ctx, t.id, playerID, t.maxStake)
+	if err != nil {
+		m.mutex.Lock()
+		delete(m.active, playerID)
+		m.mutex.Unlock()
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	m.tickets[t.id] = t
+	m.queues[gameType] = append(m.queues[gameType], t)
+	group, stake := m.findGroup(t)
+	m.mutex.Unlock()
+
+	if group != nil {
+		m.createGame(factory.(games.QuickPlay), group, stake)
+	} else {
+		go m.expire(t)
+	}
+
+	return t, nil
+}
+
+// findGroup search oldest queued tickets compatible with the ticket, found tickets removed from queue, should be called under mutex.
+func (m *matchmaker) findGroup(t *ticket) ([]*ticket, float64) {
+	group := []*ticket{t}
+	low, high := t.minStake, t.maxStake
+
+	for _, other := range m.queues[t.gameType] {
+		if len(group) == config.QUICK_PLAY_PLAYERS {
+			break
+		}
+
+		if other == t || other.playerID == t.playerID {
+			continue
+		}
+
+		newLow, newHigh := max(low, other.minStake), min(high, other.maxStake)
+		if newLow > newHigh {
+			continue
+		}
+
+		group = append(group, other)
+		low, high = newLow, newHigh
+	}
+
+	if len(group) < config.QUICK_PLAY_PLAYERS {
+		return nil, 0
+	}
+
+	for _, member := range group {
+		m.removeFromQueue(member)
+		member.setState(Matching)
+	}
+
+	// oldest ticket create the game
+	sort.Slice(group, func(i, j int) bool {
+		return group[i].createdAt.Before(group[j].createdAt)
+	})
+
+	// lowest stake acceptable for all players
+	return group, low
+}
+
+// removeFromQueue should be called under mutex.
+func (m *matchmaker) removeFromQueue(t *ticket) {
+	queue := m.queues[t.gameType]
+	for i, queued := range queue {
+		if queued == t {
+			m.queues[t.gameType] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// createGame create private game with matched players, creator is first ticket. Holds of the tickets become stakes
+// of the game in one transaction, if game not created tickets go back to queue with holds.
+func (m *matchmaker) createGame(factory games.QuickPlay, group []*ticket, stake float64) {
+	game, err := factory.QuickPlay(m.runtime.Env(), group[0].playerID, stake, uint8(len(group)))
+	if err != nil {
+		m.log.Errorw("cant create quick play game", "error", err.Error())
+		m.fail(group, err)
+		return
+	}
+
+	err = m.startGame(game, group)
+	if err != nil {
+		m.log.Errorw("cant start quick play game, tickets requeued", "error", err.Error())
+		m.requeue(group)
+		return
+	}
+
+	for _, t := range group {
+		t.mutex.Lock()
+		t.state = Matched
+		t.gameId = game.GetID()
+		t.stake = stake
+		t.mutex.Unlock()
+
+		m.finish(t)
+	}
+
+	m.log.Infow("quick play game created", "game_id", game.GetID())
+}
+
+func (m *matchmaker) startGame(game games.Game, group []*ticket) error {
+	inviteCode, err := invite.NewCode(m.runtime.Env().Random)
+	if err != nil {
+		return err
+	}
+
+	tickets := make([]string, len(group))
+	for i, t := range group {
+		tickets[i] = t.id
+	}
+
+	// seats of the game reserved for matched players
+	return m.runtime.SubscribeOnGame(game, database.Private(inviteCode), database.FromTickets(tickets, func(playerID string) error {
+		return game.AddPlayer(&games.BasePlayer{
+			Id: playerID,
+		})
+	}))
+}
+
+// fail finish tickets of the group which can not be played and release holds.
+func (m *matchmaker) fail(group []*ticket, err error) {
+	for _, t := range group {
+		t.mutex.Lock()
+		t.state = Failed
+		t.reason = err.Error()
+		t.mutex.Unlock()
+
+		errRelease := m.store.ReleaseStake(m.ctx, t.id)
+		if errRelease != nil {
+			m.log.Errorw("cant release stake of the ticket", "ticket_id", t.id, "error", errRelease.Error())
+		}
+
+		m.finish(t)
+	}
+}
+
+// requeue return tickets of the group to the queue in order of creation.
+func (m *matchmaker) requeue(group []*ticket) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, t := range group {
+		t.setState(Queued)
+		m.queues[t.gameType] = append(m.queues[t.gameType], t)
+		go m.expire(t)
+	}
+
+	queue := m.queues[group[0].gameType]
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].createdAt.Before(queue[j].createdAt)
+	})
+}
+
+// finish close ticket and forget it after QUICK_PLAY_TIMEOUT.
+func (m *matchmaker) finish(t *ticket) {
+	m.mutex.Lock()
+	if m.active[t.playerID] == t {
+		delete(m.active, t.playerID)
+	}
+	m.mutex.Unlock()
+
+	close(t.done)
+
+	timer := m.clock.NewTimer(config.QUICK_PLAY_TIMEOUT)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-m.ctx.Done():
+		case <-timer.C():
+		}
+
+		m.mutex.Lock()
+		delete(m.tickets, t.id)
+		m.mutex.Unlock()
+	}()
+}
+
+// expire drop ticket from queue with money back if no game found during QUICK_PLAY_TIMEOUT from creation.
+func (m *matchmaker) expire(t *ticket) {
+	timer := m.clock.NewTimer(t.createdAt.Add(config.QUICK_PLAY_TIMEOUT).Sub(m.clock.Now()))
+	defer timer.Stop()
+
+	select {
+	case <-t.done:
+		return
+	case <-m.ctx.Done():
+		return
+	case <-timer.C():
+	}
+
+	_, err := m.leave(t, Expired)
+	if err != nil && !errors.Is(err, ErrTicketNotQueued) {
+		m.log.Errorw("cant expire ticket", "ticket_id", t.id, "error", err.Error())
+	}
+}
+
+// leave remove queued ticket and release stake.
+func (m *matchmaker) leave(t *ticket, state TicketState) (Ticket, error) {
+	m.mutex.Lock()
+	if t.GetState() != Queued {
+		m.mutex.Unlock()
+		return nil, ErrTicketNotQueued
+	}
+	m.removeFromQueue(t)
+	t.setState(state)
+	m.mutex.Unlock()
+
+	errRelease := m.store.ReleaseStake(m.ctx, t.id)
+	if errRelease != nil {
+		m.log.Errorw("cant release stake of the ticket", "ticket_id", t.id, "error", errRelease.Error())
+	}
+
+	m.finish(t)
+
+	return t, nil
+}
+
+func (m *matchmaker) Cancel(ctx context.Context, ticketId string, playerID string) (Ticket, error) {
+	t, err := m.get(ticketId, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.leave(t, Canceled)
+}
+
+func (m *matchmaker) GetTicket(ctx context.Context, ticketId string, playerID string) (Ticket, error) {
+	return m.get(ticketId, playerID)
+}
+
+func (m *matchmaker) get(ticketId string, playerID string) (*ticket, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	t, ok := m.tickets[ticketId]
+	if !ok || t.playerID != playerID {
+		return nil, ErrTicketNotFound
+	}
+
+	return t, nil
+}
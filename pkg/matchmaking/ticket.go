@@ -0,0 +1,116 @@
+package matchmaking
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+)
+
+var (
+	_ Ticket = &ticket{}
+)
+
+type TicketState int8
+
+const (
+	Queued TicketState = iota
+	Matching
+	Matched
+	Canceled
+	Expired
+	Failed
+)
+
+// QuickPlayRequest is api payload of quick play, any stake from range is acceptable for player.
+type QuickPlayRequest struct {
+	MinStake float64 `json:"min_stake"`
+	MaxStake float64 `json:"max_stake"`
+}
+
+type Ticket interface {
+	GetId() string
+	GetPlayerId() string
+	GetState() TicketState
+	// GetGameId return id of created game when ticket matched.
+	GetGameId() string
+	// Done closed when ticket left queue: matched, canceled, expired or failed.
+	Done() <-chan struct{}
+	JSON() map[string]interface{}
+}
+
+type ticket struct {
+	mutex sync.RWMutex
+
+	id        string
+	playerID  string
+	gameType  games.GameType
+	minStake  float64
+	maxStake  float64
+	createdAt time.Time
+
+	state  TicketState
+	gameId string
+	stake  float64
+	reason string
+	done   chan struct{}
+}
+
+func (t *ticket) GetId() string {
+	return t.id
+}
+
+func (t *ticket) GetPlayerId() string {
+	return t.playerID
+}
+
+func (t *ticket) GetState() TicketState {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.state
+}
+
+func (t *ticket) GetGameId() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.gameId
+}
+
+func (t *ticket) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *ticket) setState(state TicketState) {
+	t.mutex.Lock()
+	t.state = state
+	t.mutex.Unlock()
+}
+
+func (t *ticket) JSON() map[string]interface{} {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	timeLeft := 0
+	if t.state == Queued {
+		timeLeft = int(time.Until(t.createdAt.Add(config.QUICK_PLAY_TIMEOUT)).Seconds())
+		if timeLeft < 0 {
+			timeLeft = 0
+		}
+	}
+
+	return map[string]interface{}{
+		"id":            t.id,
+		"state":         t.state,
+		"game_type":     t.gameType,
+		"min_stake":     t.minStake,
+		"max_stake":     t.maxStake,
+		"creation_time": t.createdAt,
+		"time_left":     timeLeft,
+		"stake":         t.stake,
+		"game_id":       t.gameId,
+		"error":         t.reason,
+	}
+}
@@ -0,0 +1,199 @@
+package payout
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/google/uuid"
+)
+
+var (
+	_ Scheme = &split{}
+	_ Scheme = &winnerTakesAll{}
+	_ Scheme = &topN{}
+
+	ErrUnknownScheme  = errors.New("unknown payout scheme")
+	ErrInvalidWeights = fmt.Errorf("payout weights should be positive percents with sum 100, not more than %d", config.MAX_PLAYERS)
+	ErrInvalidRake    = fmt.Errorf("rake value from 0 to %.2f percent", float64(config.MAX_RAKE))
+	ErrNoHouseAccount = errors.New("rake needs house account")
+)
+
+const (
+	Split          = "split"
+	WinnerTakesAll = "winner_takes_all"
+	TopN           = "top_n"
+
+	// full bank in basis points
+	full = 10000
+)
+
+// Scheme split bank of the game between ranked players.
+type Scheme interface {
+	Name() string
+	// Distribute return payout in nano by player id, ranking is groups of players from best to worst and
+	// players of one group share place. Sum of payouts always equal to bank,
+	// dust after integer division goes to first player of the ranking.
+	Distribute(bank uint64, ranking [][]string) map[string]uint64
+}
+
+// New return payout scheme by name, weights in percents used only by top_n.
+func New(name string, weights []float64) (Scheme, error) {
+	switch name {
+	case "", Split:
+		return &split{}, nil
+	case WinnerTakesAll:
+		return &winnerTakesAll{}, nil
+	case TopN:
+		basisPoints, err := ToBasisPoints(weights)
+		if err != nil {
+			return nil, err
+		}
+		return &topN{weights: basisPoints}, nil
+	default:
+		return nil, ErrUnknownScheme
+	}
+}
+
+// ToBasisPoints validate weights in percents and convert them to basis points.
+func ToBasisPoints(weights []float64) ([]uint64, error) {
+	if len(weights) == 0 || len(weights) > config.MAX_PLAYERS {
+		return nil, ErrInvalidWeights
+	}
+
+	sum := uint64(0)
+	basisPoints := make([]uint64, len(weights))
+	for i, weight := range weights {
+		if weight <= 0 {
+			return nil, ErrInvalidWeights
+		}
+		basisPoints[i] = uint64(math.Round(weight * 100))
+		sum += basisPoints[i]
+	}
+
+	if sum != full {
+		return nil, ErrInvalidWeights
+	}
+
+	return basisPoints, nil
+}
+
+// RakeBasisPoints validate rake in percents and convert it to basis points.
+// Rake needs house account, existence of the account checked by database layer on start.
+func RakeBasisPoints(rake float64) (uint16, error) {
+	if rake < 0 || rake > config.MAX_RAKE {
+		return 0, ErrInvalidRake
+	}
+
+	if _, err := uuid.Parse(config.Config.HouseAccountID); rake > 0 && err != nil {
+		return 0, ErrNoHouseAccount
+	}
+
+	return uint16(math.Round(rake * 100)), nil
+}
+
+// Rake return part of the bank for the house, rounded down in favor of players.
+func Rake(bank uint64, basisPoints uint16) uint64 {
+	return bank * uint64(basisPoints) / full
+}
+
+// split is default scheme, bank divided equally between winners reported by the game (first group of the ranking).
+type split struct{}
+
+func (s *split) Name() string {
+	return Split
+}
+
+func (s *split) Distribute(bank uint64, ranking [][]string) map[string]uint64 {
+	if len(ranking) == 0 {
+		return map[string]uint64{}
+	}
+
+	return share(bank, ranking[0])
+}
+
+// winnerTakesAll give bank to single player, tie on first place broken by order of the ranking (player id).
+type winnerTakesAll struct{}
+
+func (w *winnerTakesAll) Name() string {
+	return WinnerTakesAll
+}
+
+func (w *winnerTakesAll) Distribute(bank uint64, ranking [][]string) map[string]uint64 {
+	if len(ranking) == 0 || len(ranking[0]) == 0 {
+		return map[string]uint64{}
+	}
+
+	return share(bank, ranking[0][:1])
+}
+
+// topN pay to first places by weights, players of one group share weights of places they take.
+// Weights of places without players given to paid places proportionally.
+type topN struct {
+	weights []uint64
+}
+
+func (t *topN) Name() string {
+	return TopN
+}
+
+func (t *topN) Distribute(bank uint64, ranking [][]string) map[string]uint64 {
+	result := make(map[string]uint64)
+
+	type paidGroup struct {
+		players []string
+		weight  uint64
+	}
+
+	var groups []paidGroup
+	total := uint64(0)
+	place := 0
+	for _, group := range ranking {
+		if place >= len(t.weights) {
+			break
+		}
+
+		weight := uint64(0)
+		for i := place; i < place+len(group) && i < len(t.weights); i++ {
+			weight += t.weights[i]
+		}
+		place += len(group)
+
+		groups = append(groups, paidGroup{players: group, weight: weight})
+		total += weight
+	}
+
+	if total == 0 {
+		return result
+	}
+
+	paid := uint64(0)
+	for _, group := range groups {
+		amount := bank * group.weight / total / uint64(len(group.players))
+		for _, player := range group.players {
+			result[player] = amount
+			paid += amount
+		}
+	}
+
+	result[groups[0].players[0]] += bank - paid
+
+	return result
+}
+
+// share divide bank equally, dust goes to first player.
+func share(bank uint64, players []string) map[string]uint64 {
+	result := make(map[string]uint64, len(players))
+	if len(players) == 0 {
+		return result
+	}
+
+	amount := bank / uint64(len(players))
+	for _, player := range players {
+		result[player] = amount
+	}
+	result[players[0]] += bank - amount*uint64(len(players))
+
+	return result
+}
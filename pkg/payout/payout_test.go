@@ -0,0 +1,163 @@
+package payout
+
+import (
+	"testing"
+
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistribute(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		scheme  string
+		weights []float64
+		bank    uint64
+		ranking [][]string
+		result  map[string]uint64
+	}{
+		{
+			name:    "split dust to first player of the place",
+			scheme:  Split,
+			bank:    100,
+			ranking: [][]string{{"a", "b", "c"}, {"d"}},
+			result:  map[string]uint64{"a": 34, "b": 33, "c": 33},
+		},
+		{
+			name:    "split without ranking",
+			scheme:  Split,
+			bank:    100,
+			ranking: nil,
+			result:  map[string]uint64{},
+		},
+		{
+			name:    "winner takes all tie broken by order",
+			scheme:  WinnerTakesAll,
+			bank:    100,
+			ranking: [][]string{{"a", "b"}, {"c"}},
+			result:  map[string]uint64{"a": 100},
+		},
+		{
+			name:    "winner takes all without ranking",
+			scheme:  WinnerTakesAll,
+			bank:    100,
+			ranking: [][]string{},
+			result:  map[string]uint64{},
+		},
+		{
+			name:    "top n by places",
+			scheme:  TopN,
+			weights: []float64{60, 30, 10},
+			bank:    1000,
+			ranking: [][]string{{"a"}, {"b"}, {"c"}, {"d"}},
+			result:  map[string]uint64{"a": 600, "b": 300, "c": 100},
+		},
+		{
+			name:    "top n tie on first place take weights of two places",
+			scheme:  TopN,
+			weights: []float64{60, 30, 10},
+			bank:    1000,
+			ranking: [][]string{{"a", "b"}, {"c"}, {"d"}},
+			result:  map[string]uint64{"a": 450, "b": 450, "c": 100},
+		},
+		{
+			name:    "top n tie cross last paid place",
+			scheme:  TopN,
+			weights: []float64{60, 30, 10},
+			bank:    1000,
+			ranking: [][]string{{"a"}, {"b", "c", "d"}},
+			result:  map[string]uint64{"a": 601, "b": 133, "c": 133, "d": 133},
+		},
+		{
+			name:    "top n weights of empty places go to paid places",
+			scheme:  TopN,
+			weights: []float64{60, 30, 10},
+			bank:    1000,
+			ranking: [][]string{{"a"}, {"b"}},
+			result:  map[string]uint64{"a": 667, "b": 333},
+		},
+		{
+			name:    "top n without ranking",
+			scheme:  TopN,
+			weights: []float64{60, 30, 10},
+			bank:    1000,
+			ranking: nil,
+			result:  map[string]uint64{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme, err := New(tc.scheme, tc.weights)
+			require.NoError(t, err)
+
+			result := scheme.Distribute(tc.bank, tc.ranking)
+			require.Equal(t, tc.result, result)
+
+			if len(result) == 0 {
+				return
+			}
+
+			sum := uint64(0)
+			for _, amount := range result {
+				sum += amount
+			}
+			require.Equal(t, tc.bank, sum)
+		})
+	}
+}
+
+func TestNewInvalidWeights(t *testing.T) {
+	for _, weights := range [][]float64{nil, {50, 40}, {110, -10}, {100, 0}} {
+		_, err := New(TopN, weights)
+		require.ErrorIs(t, err, ErrInvalidWeights)
+	}
+
+	_, err := New("unknown", nil)
+	require.ErrorIs(t, err, ErrUnknownScheme)
+}
+
+func TestRake(t *testing.T) {
+	for _, tc := range []struct {
+		bank        uint64
+		basisPoints uint16
+		rake        uint64
+	}{
+		{bank: 1000, basisPoints: 0, rake: 0},
+		{bank: 1000, basisPoints: 250, rake: 25},
+		// rounded down in favor of players
+		{bank: 999, basisPoints: 250, rake: 24},
+		{bank: 39, basisPoints: 250, rake: 0},
+		{bank: 2_000_000_000, basisPoints: 1000, rake: 200_000_000},
+	} {
+		require.Equal(t, tc.rake, Rake(tc.bank, tc.basisPoints), "bank %d rake %d", tc.bank, tc.basisPoints)
+	}
+}
+
+func TestRakeBasisPoints(t *testing.T) {
+	house := config.Config.HouseAccountID
+	defer func() {
+		config.Config.HouseAccountID = house
+	}()
+
+	config.Config.HouseAccountID = ""
+	rake, err := RakeBasisPoints(0)
+	require.NoError(t, err)
+	require.Equal(t, uint16(0), rake)
+
+	_, err = RakeBasisPoints(2.5)
+	require.ErrorIs(t, err, ErrNoHouseAccount)
+
+	config.Config.HouseAccountID = "not uuid"
+	_, err = RakeBasisPoints(2.5)
+	require.ErrorIs(t, err, ErrNoHouseAccount)
+
+	config.Config.HouseAccountID = "6f1c1b8e-8f5e-4a8e-9f39-3c0f1b3a2d10"
+	rake, err = RakeBasisPoints(2.506)
+	require.NoError(t, err)
+	require.Equal(t, uint16(251), rake)
+
+	_, err = RakeBasisPoints(config.MAX_RAKE + 0.01)
+	require.ErrorIs(t, err, ErrInvalidRake)
+
+	_, err = RakeBasisPoints(-1)
+	require.ErrorIs(t, err, ErrInvalidRake)
+}
@@ -1,10 +1,94 @@
 package random
 
-import "hash/maphash"
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	mrand "math/rand/v2"
+	"sync"
+)
+
+var (
+	_ Source = &cryptoSource{}
+	_ Source = &seededSource{}
+)
+
+// Source of randomness for games.
+type Source interface {
+	// Uint64 return uniformly distributed 64 bits.
+	Uint64() uint64
+	// Read fill p with random bytes.
+	Read(p []byte) (int, error)
+}
+
+// Default is cryptographically secure source used in production.
+var Default = NewCrypto()
+
+type cryptoSource struct {
+}
+
+func (c *cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		panic(err)
+	}
+
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (c *cryptoSource) Read(p []byte) (int, error) {
+	return rand.Read(p)
+}
+
+// NewCrypto return source based on crypto/rand.
+func NewCrypto() Source {
+	return &cryptoSource{}
+}
+
+type seededSource struct {
+	mutex sync.Mutex
+	rng   *mrand.ChaCha8
+}
+
+func (s *seededSource) Uint64() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rng.Uint64()
+}
+
+func (s *seededSource) Read(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.rng.Read(p)
+}
+
+// NewSeeded return deterministic source, same seed always produce same sequence. Use for tests and replays.
+func NewSeeded(seed uint64) Source {
+	var key [32]byte
+	binary.BigEndian.PutUint64(key[:8], seed)
+
+	return &seededSource{
+		rng: mrand.NewChaCha8(key),
+	}
+}
+
+// Uint64n return random between 0..max-1 from source without modulo bias.
+func Uint64n(src Source, max uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%max
+	for {
+		value := src.Uint64()
+		if value < limit {
+			return value % max
+		}
+	}
+}
 
 // GetRandom return random between 0..max-1.
 func GetRandom(max uint64) uint64 {
-	return new(maphash.Hash).Sum64() % max
+	return Uint64n(Default, max)
 }
 
 // GetRandomInRange return random from a to b.
@@ -0,0 +1,84 @@
+package random
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sequence return values in given order, used to drive rejection sampling.
+type sequence struct {
+	values []uint64
+	calls  int
+}
+
+func (s *sequence) Uint64() uint64 {
+	value := s.values[s.calls]
+	s.calls += 1
+	return value
+}
+
+func (s *sequence) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestUint64nRejectBiasedTail(t *testing.T) {
+	const max = 10
+	limit := uint64(math.MaxUint64 - math.MaxUint64%max)
+
+	src := &sequence{
+		values: []uint64{limit, math.MaxUint64, limit + 3, 27},
+	}
+	require.Equal(t, uint64(7), Uint64n(src, max))
+	require.Equal(t, 4, src.calls)
+
+	src = &sequence{
+		values: []uint64{limit - 1},
+	}
+	require.Equal(t, (limit-1)%max, Uint64n(src, max))
+	require.Equal(t, 1, src.calls)
+}
+
+func TestUint64nInRange(t *testing.T) {
+	src := NewSeeded(42)
+
+	for _, max := range []uint64{1, 2, 3, 6, 100, 1 << 63, math.MaxUint64} {
+		for i := 0; i < 1000; i++ {
+			require.Less(t, Uint64n(src, max), max)
+		}
+	}
+}
+
+func TestUint64nCoverAllValues(t *testing.T) {
+	const max = 6
+	src := NewSeeded(7)
+
+	seen := make(map[uint64]int, max)
+	for i := 0; i < 6000; i++ {
+		seen[Uint64n(src, max)] += 1
+	}
+
+	require.Len(t, seen, max)
+	for value, count := range seen {
+		require.Greater(t, count, 800, "value %d", value)
+	}
+}
+
+func TestNewSeededReproducible(t *testing.T) {
+	a, b := NewSeeded(1), NewSeeded(1)
+	for i := 0; i < 100; i++ {
+		require.Equal(t, a.Uint64(), b.Uint64())
+	}
+
+	bufA, bufB := make([]byte, 64), make([]byte, 64)
+	_, err := a.Read(bufA)
+	require.NoError(t, err)
+	_, err = b.Read(bufB)
+	require.NoError(t, err)
+	require.Equal(t, bufA, bufB)
+
+	other := NewSeeded(2)
+	fresh := NewSeeded(1)
+	require.NotEqual(t, fresh.Uint64(), other.Uint64())
+}
@@ -0,0 +1,155 @@
+package replay
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/xssnick/tonutils-go/tlb"
+)
+
+var (
+	ErrWinnersNotRecorded = errors.New("winners of the game not recorded")
+)
+
+// Step is one public event of the game timeline, payload is typed when game publish versioned payloads.
+type Step struct {
+	Timestamp time.Time           `json:"timestamp"`
+	Type      games.GameEventType `json:"type"`
+	Msg       string              `json:"msg"`
+	Players   []string            `json:"players,omitempty"`
+	Payload   interface{}         `json:"payload,omitempty"`
+}
+
+// Verification compare recorded winners with winners recomputed from inputs of the game.
+type Verification struct {
+	Supported       bool     `json:"supported"`
+	RecordedWinners []string `json:"recorded_winners"`
+	ReplayedWinners []string `json:"replayed_winners"`
+	Match           bool     `json:"match"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// Build return timeline, payouts and verification of the game, record should be loaded with History (ordered by timestamp) and Winners.
+func Build(rec database.GameRecord) map[string]interface{} {
+	events := rec.GetEvents()
+
+	timeline := make([]*Step, len(events))
+	for i, event := range events {
+		var payload interface{} = event.MD
+		if typed, err := games.DecodePayload(rec.GetGameType(), event); err == nil {
+			payload = typed
+		}
+
+		timeline[i] = &Step{
+			Timestamp: event.Timestamp,
+			Type:      event.Type,
+			Msg:       event.Msg,
+			Players:   event.Players,
+			Payload:   payload,
+		}
+	}
+
+	payouts := make(map[string]string, len(rec.GetPayouts()))
+	for id, amount := range rec.GetPayouts() {
+		payouts[id] = formatNano(amount)
+	}
+
+	return map[string]interface{}{
+		"game":         rec.JSON(),
+		"timeline":     timeline,
+		"payouts":      payouts,
+		"verification": verify(rec.GetGameType(), events),
+	}
+}
+
+func verify(gameType games.GameType, events []*games.RecordedEvent) *Verification {
+	v := &Verification{}
+
+	factory, err := games.GetFactory(gameType)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+
+	replayer, ok := factory.(games.Replayer)
+	if !ok {
+		v.Error = games.ErrNotReplayable.Error()
+		return v
+	}
+	v.Supported = true
+
+	banked, ok := factory.(games.HouseBankedFactory)
+	recorded, err := recordedWinners(events, ok && banked.HouseBanked())
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+
+	replayed, err := replayer.Replay(events)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+
+	v.RecordedWinners = sortedIds(recorded)
+	v.ReplayedWinners = sortedIds(replayed)
+	v.Match = equal(v.RecordedWinners, v.ReplayedWinners)
+
+	return v
+}
+
+// recordedWinners return players of Winners event, empty list when game finished with money back
+// or when nobody won against house.
+func recordedWinners(events []*games.RecordedEvent, banked bool) ([]string, error) {
+	result := games.LastEvent(events, games.Winners, games.NoWinners)
+	if result == nil {
+		return nil, games.ErrNotReplayable
+	}
+
+	if result.Type == games.NoWinners {
+		return nil, nil
+	}
+
+	// bank of round without winners goes to house, compared with replayed winners as no winners
+	if banked && len(result.Players) == 0 {
+		return nil, nil
+	}
+
+	if len(result.Players) == 0 {
+		return nil, ErrWinnersNotRecorded
+	}
+
+	return result.Players, nil
+}
+
+func sortedIds(ids []string) []string {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatNano(amount int64) string {
+	if amount < 0 {
+		return "-" + tlb.FromNanoTONU(uint64(-amount)).String()
+	}
+
+	return tlb.FromNanoTONU(uint64(amount)).String()
+}
@@ -0,0 +1,30 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedWinners(t *testing.T) {
+	noPlayers := []*games.RecordedEvent{{Type: games.Start}, {Type: games.Winners}}
+
+	_, err := recordedWinners(noPlayers, false)
+	require.ErrorIs(t, err, ErrWinnersNotRecorded)
+
+	winners, err := recordedWinners(noPlayers, true)
+	require.NoError(t, err)
+	require.Empty(t, winners)
+
+	winners, err = recordedWinners([]*games.RecordedEvent{{Type: games.Winners, Players: []string{"b", "a"}}}, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a"}, winners)
+
+	winners, err = recordedWinners([]*games.RecordedEvent{{Type: games.NoWinners}}, false)
+	require.NoError(t, err)
+	require.Empty(t, winners)
+
+	_, err = recordedWinners([]*games.RecordedEvent{{Type: games.Start}}, false)
+	require.ErrorIs(t, err, games.ErrNotReplayable)
+}
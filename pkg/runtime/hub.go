@@ -0,0 +1,253 @@
+package runtime
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PxyUp/ton_games_example/games"
+)
+
+var (
+	_ Hub          = &hub{}
+	_ Subscription = &subscription{}
+
+	ErrSlowSubscriber = errors.New("subscriber disconnected, events not read in time")
+)
+
+// Policy define what hub do when buffer of the subscriber is full.
+type Policy int8
+
+const (
+	// Block wait until subscriber read event, game wait as well, used only for persistence.
+	Block Policy = iota
+	// DropOldest drop oldest buffered event to free space for new one.
+	DropOldest
+	// Disconnect close subscription, subscriber should subscribe again.
+	Disconnect
+)
+
+const (
+	// AllGames subscribe on events of every game.
+	AllGames = ""
+	// Lobby subscribe only on Created events of new games, not taken by any game id (uuid).
+	Lobby = "lobby"
+)
+
+type Subscription interface {
+	// Events closed when game finished (not for AllGames and Lobby), subscription closed or disconnected.
+	Events() <-chan games.GameEvent
+	// Dropped return number of events dropped by DropOldest policy.
+	Dropped() uint64
+	// Err return ErrSlowSubscriber when subscription closed by Disconnect policy.
+	Err() error
+	Close()
+}
+
+// Hub fan out events of the games to subscribers, every game has own pump so slow subscriber never hold the game with own buffer free.
+type Hub interface {
+	// Subscribe on events of active game, AllGames as id subscribe on all games, Lobby only on created games.
+	// Buffer is at least one event, subscribers out of the runtime should not use Block policy.
+	Subscribe(gameId string, buffer int, policy Policy) (Subscription, error)
+}
+
+type hub struct {
+	mutex sync.RWMutex
+	// subscriptions by game id, AllGames and Lobby keys for subscriptions not bound to the game
+	subs map[string]map[*subscription]struct{}
+}
+
+func newHub() *hub {
+	return &hub{
+		subs: map[string]map[*subscription]struct{}{
+			AllGames: {},
+			Lobby:    {},
+		},
+	}
+}
+
+func (h *hub) Subscribe(gameId string, buffer int, policy Policy) (Subscription, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	subs, ok := h.subs[gameId]
+	if !ok {
+		return nil, ErrInvalidGameID
+	}
+
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	s := &subscription{
+		hub:    h,
+		gameId: gameId,
+		policy: policy,
+		events: make(chan games.GameEvent, buffer),
+		done:   make(chan struct{}),
+	}
+	subs[s] = struct{}{}
+
+	return s, nil
+}
+
+// open allow subscriptions on the game, should be called before pump of the game started.
+func (h *hub) open(gameId string) {
+	h.mutex.Lock()
+	if _, ok := h.subs[gameId]; !ok {
+		h.subs[gameId] = make(map[*subscription]struct{})
+	}
+	h.mutex.Unlock()
+}
+
+// pump read all events of the game and publish them, subscriptions of the game closed after last event.
+func (h *hub) pump(gameId string, updates <-chan games.GameEvent, rewrite func(games.GameEvent) games.GameEvent) {
+	for event := range updates {
+		h.publish(gameId, rewrite(event))
+	}
+
+	h.mutex.Lock()
+	subs := h.subs[gameId]
+	delete(h.subs, gameId)
+	h.mutex.Unlock()
+
+	for s := range subs {
+		s.close(nil)
+	}
+}
+
+func (h *hub) publish(gameId string, event games.GameEvent) {
+	h.deliver(event, AllGames, gameId)
+}
+
+// announce publish event of new game to subscribers of all games and lobby, game itself has no subscribers yet.
+func (h *hub) announce(event games.GameEvent) {
+	h.deliver(event, AllGames, Lobby)
+}
+
+// deliver send event to subscribers of the keys, so lobby subscriber never see events of running games.
+func (h *hub) deliver(event games.GameEvent, keys ...string) {
+	h.mutex.RLock()
+	subs := []*subscription{}
+	for _, key := range keys {
+		for s := range h.subs[key] {
+			subs = append(subs, s)
+		}
+	}
+	h.mutex.RUnlock()
+
+	for _, s := range subs {
+		if !s.deliver(event) {
+			h.remove(s)
+		}
+	}
+}
+
+func (h *hub) remove(s *subscription) {
+	h.mutex.Lock()
+	if subs, ok := h.subs[s.gameId]; ok {
+		delete(subs, s)
+	}
+	h.mutex.Unlock()
+}
+
+type subscription struct {
+	hub    *hub
+	gameId string
+	policy Policy
+
+	// mutex serialize sends with close of events
+	mutex   sync.Mutex
+	events  chan games.GameEvent
+	closed  bool
+	err     error
+	dropped atomic.Uint64
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (s *subscription) Events() <-chan games.GameEvent {
+	return s.events
+}
+
+func (s *subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *subscription) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.close(nil)
+	s.hub.remove(s)
+}
+
+// close unblock pending send and close events, buffered events still can be read.
+func (s *subscription) close(err error) {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+
+	s.mutex.Lock()
+	s.closeLocked(err)
+	s.mutex.Unlock()
+}
+
+func (s *subscription) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.err = err
+	close(s.events)
+}
+
+// deliver send event by policy of the subscription, return false when subscription closed.
+func (s *subscription) deliver(event games.GameEvent) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	switch s.policy {
+	case Block:
+		select {
+		case s.events <- event:
+		case <-s.done:
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.events <- event:
+				return true
+			default:
+			}
+
+			select {
+			case <-s.events:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case Disconnect:
+		select {
+		case s.events <- event:
+		default:
+			s.doneOnce.Do(func() {
+				close(s.done)
+			})
+			s.closeLocked(ErrSlowSubscriber)
+			return false
+		}
+	}
+
+	return !s.closed
+}
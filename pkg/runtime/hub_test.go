@@ -0,0 +1,173 @@
+package runtime
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/stretchr/testify/require"
+)
+
+const gameId = "game"
+
+func event(n int) games.GameEvent {
+	return games.NewGameEvent(gameId, games.Update, strconv.Itoa(n), true, nil, nil)
+}
+
+func newTestHub(t *testing.T, buffer int, policy Policy) (*hub, Subscription) {
+	h := newHub()
+	h.open(gameId)
+
+	s, err := h.Subscribe(gameId, buffer, policy)
+	require.NoError(t, err)
+
+	return h, s
+}
+
+// publishAsync publish events from own goroutine like pump of the game, returned channel closed when all delivered.
+func publishAsync(h *hub, from int, to int) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := from; n <= to; n++ {
+			h.publish(gameId, event(n))
+		}
+	}()
+
+	return done
+}
+
+func requireMsgs(t *testing.T, s Subscription, msgs ...string) {
+	for _, msg := range msgs {
+		select {
+		case e, ok := <-s.Events():
+			require.True(t, ok, "events closed, expected %s", msg)
+			require.Equal(t, msg, e.Msg())
+		case <-time.After(time.Second):
+			require.FailNow(t, "no event", "expected %s", msg)
+		}
+	}
+}
+
+func requireClosed(t *testing.T, s Subscription) {
+	select {
+	case e, ok := <-s.Events():
+		require.False(t, ok, "unexpected event %v", e)
+	case <-time.After(time.Second):
+		require.FailNow(t, "events not closed")
+	}
+}
+
+func requireBlocked(t *testing.T, done <-chan struct{}) {
+	select {
+	case <-done:
+		require.FailNow(t, "publish not blocked by stalled subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func requireDone(t *testing.T, done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "publish still blocked")
+	}
+}
+
+func TestHubBlockNeverDrop(t *testing.T) {
+	h, s := newTestHub(t, 1, Block)
+
+	done := publishAsync(h, 1, 3)
+	requireBlocked(t, done)
+
+	// stalled subscriber hold publisher, every event delivered in order
+	requireMsgs(t, s, "1", "2", "3")
+	requireDone(t, done)
+	require.Equal(t, uint64(0), s.Dropped())
+	require.NoError(t, s.Err())
+}
+
+func TestHubBlockCloseDuringSend(t *testing.T) {
+	h, s := newTestHub(t, 1, Block)
+
+	done := publishAsync(h, 1, 2)
+	requireBlocked(t, done)
+
+	s.Close()
+	requireDone(t, done)
+
+	// buffered event still can be read after close
+	requireMsgs(t, s, "1")
+	requireClosed(t, s)
+	require.NoError(t, s.Err())
+
+	// closed subscription removed from the hub, next publish not blocked
+	requireDone(t, publishAsync(h, 3, 3))
+	require.Empty(t, h.subs[gameId])
+}
+
+func TestHubDropOldest(t *testing.T) {
+	h, s := newTestHub(t, 2, DropOldest)
+
+	requireDone(t, publishAsync(h, 1, 5))
+	require.Equal(t, uint64(3), s.Dropped())
+	requireMsgs(t, s, "4", "5")
+
+	requireDone(t, publishAsync(h, 6, 6))
+	requireMsgs(t, s, "6")
+	require.Equal(t, uint64(3), s.Dropped())
+	require.NoError(t, s.Err())
+}
+
+func TestHubDisconnectStalledSubscriber(t *testing.T) {
+	h, s := newTestHub(t, 1, Disconnect)
+	other, err := h.Subscribe(gameId, 4, Block)
+	require.NoError(t, err)
+
+	requireDone(t, publishAsync(h, 1, 3))
+
+	requireMsgs(t, s, "1")
+	requireClosed(t, s)
+	require.ErrorIs(t, s.Err(), ErrSlowSubscriber)
+	require.NotContains(t, h.subs[gameId], s)
+
+	// other subscribers of the game not affected
+	requireMsgs(t, other, "1", "2", "3")
+	require.NoError(t, other.Err())
+}
+
+func TestHubPumpCloseGameSubscriptions(t *testing.T) {
+	h, s := newTestHub(t, 4, Block)
+	all, err := h.Subscribe(AllGames, 4, Block)
+	require.NoError(t, err)
+	lobby, err := h.Subscribe(Lobby, 4, Block)
+	require.NoError(t, err)
+
+	updates := make(chan games.GameEvent)
+	pumped := make(chan struct{})
+	go func() {
+		defer close(pumped)
+		h.pump(gameId, updates, func(e games.GameEvent) games.GameEvent {
+			return e
+		})
+	}()
+
+	updates <- event(1)
+	close(updates)
+	requireDone(t, pumped)
+
+	requireMsgs(t, s, "1")
+	requireClosed(t, s)
+	requireMsgs(t, all, "1")
+
+	// lobby see only announced games
+	select {
+	case e := <-lobby.Events():
+		require.FailNow(t, "lobby got event of running game", "%v", e)
+	default:
+	}
+
+	_, err = h.Subscribe(gameId, 1, Block)
+	require.ErrorIs(t, err, ErrInvalidGameID)
+}
@@ -2,12 +2,17 @@ package runtime
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"sync"
 
 	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
 	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/invite"
 	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/random"
 )
 
 type runtime struct {
@@ -15,13 +20,67 @@ type runtime struct {
 
 	mutex sync.Mutex
 	kv    map[string]games.Game
+	// canceled games which abort should be recorded as cancel
+	canceled map[string]struct{}
 
 	log   logger.Logger
 	store database.DB
+	env   *games.Env
+	hub   *hub
+
+	hooksMutex sync.RWMutex
+	hooks      []ResultHook
+}
+
+// ResultHook called after result of the game stored, winners empty when game finished without winners, failed or aborted.
+type ResultHook func(game games.Game, winners []games.Player)
+
+func (r *runtime) OnResult(hook ResultHook) {
+	r.hooksMutex.Lock()
+	r.hooks = append(r.hooks, hook)
+	r.hooksMutex.Unlock()
+}
+
+func (r *runtime) notifyResult(game games.Game, winners []games.Player) {
+	r.hooksMutex.RLock()
+	hooks := r.hooks
+	r.hooksMutex.RUnlock()
+
+	for _, hook := range hooks {
+		hook(game, winners)
+	}
+}
+
+func (r *runtime) Env() *games.Env {
+	return r.env
+}
+
+func (r *runtime) Subscribe(gameId string, buffer int, policy Policy) (Subscription, error) {
+	return r.hub.Subscribe(gameId, buffer, policy)
+}
+
+// follow start pump of the game with persistence subscribed first, so store see every event of the game.
+func (r *runtime) follow(game games.Game, gameCreated <-chan struct{}, snapshotStored bool) {
+	gameId := game.GetID()
+	r.hub.open(gameId)
+
+	store, _ := r.hub.Subscribe(gameId, config.HUB_STORE_BUFFER, Block)
+	go r.watch(game, store, gameCreated, snapshotStored)
+	go r.hub.pump(gameId, game.Updates(), func(event games.GameEvent) games.GameEvent {
+		if event.GetEventType() == games.Abort && r.isCanceled(gameId) {
+			return games.NewGameEvent(gameId, games.Canceled, "game canceled by creator", true, event.Players(), nil)
+		}
+		return event
+	})
 }
 
 func (r *runtime) JoinGameWithAction(ctx context.Context, game games.Game, playerID string, action games.PlayerEvent) (games.Game, error) {
-	_, err := r.store.JoinGame(ctx, game, playerID, func() error {
+	stake, err := games.StakeFor(game, action)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.store.JoinGame(ctx, game, playerID, stake, func() error {
 		return game.AddPlayerWithAction(&games.BasePlayer{
 			Id: playerID,
 		}, action)
@@ -33,6 +92,30 @@ func (r *runtime) JoinGameWithAction(ctx context.Context, game games.Game, playe
 	return game, nil
 }
 
+func (r *runtime) CancelGame(ctx context.Context, game games.Game, playerID string) (games.Game, error) {
+	r.mutex.Lock()
+	r.canceled[game.GetID()] = struct{}{}
+	r.mutex.Unlock()
+
+	_, err := r.store.CancelGame(ctx, game, playerID, game.Abort)
+	if err != nil {
+		r.mutex.Lock()
+		delete(r.canceled, game.GetID())
+		r.mutex.Unlock()
+		return nil, err
+	}
+
+	return game, nil
+}
+
+func (r *runtime) isCanceled(gameId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.canceled[gameId]
+	return ok
+}
+
 func (r *runtime) LeftGame(ctx context.Context, game games.Game, playerID string) (games.Game, error) {
 	_, err := r.store.LeftGame(ctx, game, playerID, func() error {
 		return game.RemovePlayer(&games.BasePlayer{
@@ -48,8 +131,37 @@ func (r *runtime) LeftGame(ctx context.Context, game games.Game, playerID string
 
 var (
 	ErrInvalidGameID = errors.New("invalid game id")
+	ErrNotRestorable = errors.New("game type not support restore")
+	ErrInviteNeeded  = errors.New("private game, invite needed")
+	ErrInvalidInvite = errors.New("invalid invite")
 )
 
+func (r *runtime) CheckInvite(ctx context.Context, game games.Game, inviteCode string) error {
+	rec, err := r.store.GetGameById(ctx, game.GetID())
+	if err != nil {
+		return err
+	}
+
+	if !rec.IsPrivate() {
+		return nil
+	}
+
+	if inviteCode == "" {
+		return ErrInviteNeeded
+	}
+
+	if subtle.ConstantTimeCompare([]byte(inviteCode), []byte(rec.GetInviteCode())) == 1 {
+		return nil
+	}
+
+	gameId, errToken := invite.Verify(config.Config.PayloadSignatureKey, inviteCode)
+	if errToken == nil && gameId == game.GetID() {
+		return nil
+	}
+
+	return ErrInvalidInvite
+}
+
 func (r *runtime) GetGame(ctx context.Context, id string) (games.Game, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -67,7 +179,7 @@ func (r *runtime) SendUserEvent(ctx context.Context, game games.Game, event game
 }
 
 func (r *runtime) JoinGame(ctx context.Context, game games.Game, playerID string) (games.Game, error) {
-	_, err := r.store.JoinGame(ctx, game, playerID, func() error {
+	_, err := r.store.JoinGame(ctx, game, playerID, game.GetCost(), func() error {
 		return game.AddPlayer(&games.BasePlayer{
 			Id: playerID,
 		})
@@ -79,7 +191,7 @@ func (r *runtime) JoinGame(ctx context.Context, game games.Game, playerID string
 	return game, nil
 }
 
-func (r *runtime) SubscribeOnGame(game games.Game) error {
+func (r *runtime) SubscribeOnGame(game games.Game, opts ...database.GameOption) error {
 	r.mutex.Lock()
 	gameId := game.GetID()
 	_, exists := r.kv[gameId]
@@ -96,70 +208,9 @@ func (r *runtime) SubscribeOnGame(game games.Game) error {
 
 	gameCreated := make(chan struct{})
 
-	go func() {
-		defer func() {
-			r.mutex.Lock()
-			delete(r.kv, gameId)
-			r.mutex.Unlock()
-		}()
-		<-gameCreated
-		for event := range game.Updates() {
-			if event.IsPublic() {
-				_, errAppend := r.store.AppendEvent(r.ctx, game, event)
-				if errAppend != nil {
-					r.log.Errorw("cant append event to the game", "error", errAppend.Error())
-				}
-			}
-			switch event.GetEventType() {
-			case games.NoWinners:
-				_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
-				if errUnlockPlayer != nil {
-					r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
-				}
-			case games.Finished:
-				_, errState := r.store.ChangeGameState(r.ctx, game, games.GameFinished)
-				if errState != nil {
-					r.log.Errorw("cant update game state", "error", errState.Error())
-				}
-			case games.Start:
-				_, errState := r.store.ChangeGameState(r.ctx, game, games.GameInProgress)
-				if errState != nil {
-					r.log.Errorw("cant update game state", "error", errState.Error())
-				}
-			case games.Abort:
-				_, errState := r.store.ChangeGameState(r.ctx, game, games.GameError)
-				if errState != nil {
-					r.log.Errorw("cant update game state", "error", errState.Error())
-				}
-				_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
-				if errUnlockPlayer != nil {
-					r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
-				}
-			case games.Winners:
-				_, errWinners := r.store.StoreWinners(r.ctx, game, event.Players())
-				if errWinners != nil {
-					r.log.Errorw("cant store winners", "error", errWinners.Error())
-				}
-			case games.PlayerJoin:
-				continue
-			case games.PlayerLeft:
-				continue
-			case games.Error:
-				_, errState := r.store.ChangeGameState(r.ctx, game, games.GameError)
-				if errState != nil {
-					r.log.Errorw("cant update game state", "error", errState.Error())
-				}
-				_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
-				if errUnlockPlayer != nil {
-					r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
-				}
-			default:
-				continue
-			}
-		}
-	}()
+	r.follow(game, gameCreated, false)
 
-	_, err = r.store.CreateGame(r.ctx, game)
+	rec, err := r.store.CreateGame(r.ctx, game, opts...)
 	if err != nil {
 		_ = game.Abort()
 		r.mutex.Unlock()
@@ -171,9 +222,166 @@ func (r *runtime) SubscribeOnGame(game games.Game) error {
 	r.kv[gameId] = game
 	r.mutex.Unlock()
 
+	r.hub.announce(games.NewGameEvent(gameId, games.Created, "game is created", false, nil, rec.JSON()))
+
 	return nil
 }
 
+// watch apply events of the game to the store until game finished, gameCreated closed when game record exists.
+func (r *runtime) watch(game games.Game, store Subscription, gameCreated <-chan struct{}, snapshotStored bool) {
+	gameId := game.GetID()
+	defer func() {
+		r.mutex.Lock()
+		delete(r.kv, gameId)
+		delete(r.canceled, gameId)
+		r.mutex.Unlock()
+	}()
+	<-gameCreated
+	for event := range store.Events() {
+		snapshotStored = r.snapshot(game, event, snapshotStored)
+		if event.IsPublic() {
+			_, errAppend := r.store.AppendEvent(r.ctx, game, event)
+			if errAppend != nil {
+				r.log.Errorw("cant append event to the game", "error", errAppend.Error())
+			}
+		}
+		switch event.GetEventType() {
+		case games.NoWinners:
+			_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
+			if errUnlockPlayer != nil {
+				r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
+			}
+			r.notifyResult(game, nil)
+		case games.Finished:
+			_, errState := r.store.ChangeGameState(r.ctx, game, games.GameFinished)
+			if errState != nil {
+				r.log.Errorw("cant update game state", "error", errState.Error())
+			}
+		case games.Start:
+			_, errState := r.store.ChangeGameState(r.ctx, game, games.GameInProgress)
+			if errState != nil {
+				r.log.Errorw("cant update game state", "error", errState.Error())
+			}
+		case games.Abort:
+			_, errState := r.store.ChangeGameState(r.ctx, game, games.GameError)
+			if errState != nil {
+				r.log.Errorw("cant update game state", "error", errState.Error())
+			}
+			_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
+			if errUnlockPlayer != nil {
+				r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
+			}
+			r.notifyResult(game, nil)
+		case games.Winners:
+			_, errWinners := r.store.StoreWinners(r.ctx, game, event.Players(), games.RankingOf(event))
+			if errWinners != nil {
+				r.log.Errorw("cant store winners", "error", errWinners.Error())
+			}
+			r.notifyResult(game, event.Players())
+		case games.Canceled:
+			// state and locks changed by CancelGame
+			r.notifyResult(game, nil)
+		case games.PlayerJoin:
+			continue
+		case games.PlayerLeft:
+			continue
+		case games.Error:
+			_, errState := r.store.ChangeGameState(r.ctx, game, games.GameError)
+			if errState != nil {
+				r.log.Errorw("cant update game state", "error", errState.Error())
+			}
+			_, errUnlockPlayer := r.store.UnlockAllPlayer(r.ctx, game)
+			if errUnlockPlayer != nil {
+				r.log.Errorw("cant unlock all players", "error", errUnlockPlayer.Error())
+			}
+			r.notifyResult(game, nil)
+		default:
+			continue
+		}
+	}
+}
+
+// snapshot store snapshot carried by the event, public event without snapshot means game reach state
+// which can not be restored (result, abort) and stored snapshot dropped. Streamed only events (crash ticks) keep it.
+// Return true if snapshot stored.
+func (r *runtime) snapshot(game games.Game, event games.GameEvent, stored bool) bool {
+	if snapshotEvent, ok := event.(games.SnapshotEvent); ok && snapshotEvent.GetSnapshot() != nil {
+		errStore := r.store.StoreSnapshot(r.ctx, snapshotEvent.GetSnapshot())
+		if errStore != nil {
+			r.log.Errorw("cant store game snapshot", "error", errStore.Error())
+			return stored
+		}
+		return true
+	}
+
+	if !stored || !event.IsPublic() {
+		return stored
+	}
+
+	errDelete := r.store.DeleteSnapshot(r.ctx, game.GetID())
+	if errDelete != nil {
+		r.log.Errorw("cant delete game snapshot", "error", errDelete.Error())
+		return true
+	}
+
+	return false
+}
+
+// restore rehydrate games from snapshots, game which can not be restored finished with money back.
+func (r *runtime) restore() {
+	snapshots, err := r.store.GetSnapshots(r.ctx)
+	if err != nil {
+		r.log.Errorw("cant get game snapshots", "error", err.Error())
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		game, errRestore := r.restoreGame(snapshot)
+		if errRestore != nil {
+			r.log.Errorw("cant restore game", "game_id", snapshot.GameID, "error", errRestore.Error())
+			errDiscard := r.store.DiscardSnapshot(r.ctx, snapshot.GameID)
+			if errDiscard != nil {
+				r.log.Errorw("cant discard game snapshot", "game_id", snapshot.GameID, "error", errDiscard.Error())
+			}
+			continue
+		}
+
+		gameCreated := make(chan struct{})
+		close(gameCreated)
+		r.follow(game, gameCreated, true)
+
+		r.mutex.Lock()
+		r.kv[game.GetID()] = game
+		r.mutex.Unlock()
+
+		r.log.Infow("game restored", "game_id", game.GetID())
+	}
+}
+
+func (r *runtime) restoreGame(snapshot *games.Snapshot) (games.Game, error) {
+	factory, err := games.GetFactory(snapshot.GameType)
+	if err != nil {
+		return nil, err
+	}
+
+	restorer, ok := factory.(games.Restorer)
+	if !ok {
+		return nil, ErrNotRestorable
+	}
+
+	game, err := restorer.Restore(r.env, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	err = game.Start(r.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
 func (r *runtime) ListOfGames(ctx context.Context) ([]games.Game, error) {
 	r.mutex.Lock()
 	list := make([]games.Game, len(r.kv))
@@ -193,15 +401,36 @@ type Runtime interface {
 	JoinGame(ctx context.Context, game games.Game, playerID string) (games.Game, error)
 	JoinGameWithAction(ctx context.Context, game games.Game, playerID string, action games.PlayerEvent) (games.Game, error)
 	LeftGame(ctx context.Context, game games.Game, playerID string) (games.Game, error)
+	// CancelGame abort game by creator while nobody else joined, money back.
+	CancelGame(ctx context.Context, game games.Game, playerID string) (games.Game, error)
 	SendUserEvent(ctx context.Context, game games.Game, event games.PlayerEvent) error
-	SubscribeOnGame(game games.Game) error
+	SubscribeOnGame(game games.Game, opts ...database.GameOption) error
+	// CheckInvite verify invite code or signed invite token for private game, public games always pass.
+	CheckInvite(ctx context.Context, game games.Game, inviteCode string) error
+	// OnResult register hook for Winners, NoWinners, Canceled, Abort and Error events of all games.
+	OnResult(hook ResultHook)
+	// Env return dependencies for creation of new games.
+	Env() *games.Env
+	Hub
 }
 
-func New(ctx context.Context, store database.DB, logger2 logger.Logger) Runtime {
-	return &runtime{
+// New create runtime and restore games from snapshots stored before restart.
+func New(ctx context.Context, store database.DB, logger2 logger.Logger, clk clock.Clock) Runtime {
+	r := &runtime{
 		store: store,
 		ctx:   ctx,
 		log:   logger2,
 		kv:    make(map[string]games.Game),
+		hub:   newHub(),
+
+		canceled: make(map[string]struct{}),
+		env: &games.Env{
+			Clock:  clk,
+			Random: random.Default,
+		},
 	}
+
+	r.restore()
+
+	return r
 }
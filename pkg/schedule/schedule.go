@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/runtime"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHostNotSupported = errors.New("game type not support house games")
+	ErrNoHouseAccount   = errors.New("house games need valid house account")
+	ErrInvalidInterval  = fmt.Errorf("house game interval should be at least %s", config.MIN_SCHEDULE_INTERVAL.String())
+)
+
+// Entry is recurring house game, settings is create payload of the game type.
+type Entry struct {
+	GameType games.GameType  `json:"game_type"`
+	Every    int             `json:"every"`
+	Settings json.RawMessage `json:"settings"`
+}
+
+func (e *Entry) every() time.Duration {
+	return time.Duration(e.Every) * time.Second
+}
+
+// Parse read schedule from json list of entries.
+func Parse(raw string) ([]*Entry, error) {
+	var entries []*Entry
+	err := json.Unmarshal([]byte(raw), &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+type scheduler struct {
+	ctx   context.Context
+	house string
+
+	store   database.DB
+	runtime runtime.Runtime
+	log     logger.Logger
+	clock   clock.Clock
+}
+
+// Run validate entries and create house games by schedule through the runtime until ctx done.
+func Run(ctx context.Context, store database.DB, rt runtime.Runtime, entries []*Entry, logger2 logger.Logger) error {
+	s := &scheduler{
+		ctx:     ctx,
+		house:   config.Config.HouseAccountID,
+		store:   store,
+		runtime: rt,
+		log:     logger2,
+		clock:   rt.Env().Clock,
+	}
+
+	if _, err := uuid.Parse(s.house); err != nil {
+		return ErrNoHouseAccount
+	}
+
+	hosts := make([]games.Hosted, len(entries))
+	for i, entry := range entries {
+		factory, err := games.GetFactory(entry.GameType)
+		if err != nil {
+			return err
+		}
+
+		host, ok := factory.(games.Hosted)
+		if !ok {
+			return fmt.Errorf("%s: %w", factory.Name(), ErrHostNotSupported)
+		}
+
+		if entry.every() < config.MIN_SCHEDULE_INTERVAL {
+			return fmt.Errorf("%s: %w", factory.Name(), ErrInvalidInterval)
+		}
+
+		// settings validated by factory
+		_, err = host.Host(rt.Env(), s.house, entry.Settings)
+		if err != nil {
+			return fmt.Errorf("%s: %w", factory.Name(), err)
+		}
+
+		hosts[i] = host
+	}
+
+	for i, entry := range entries {
+		go s.loop(hosts[i], entry)
+	}
+
+	return nil
+}
+
+// loop create game of the entry every interval, new game skipped while previous one still wait first player.
+func (s *scheduler) loop(host games.Hosted, entry *Entry) {
+	previous := ""
+	for {
+		if s.waitFirstPlayer(previous) {
+			s.log.Infow("previous house game still empty, skip", "game_id", previous)
+		} else {
+			gameId, err := s.create(host, entry)
+			if err != nil {
+				s.log.Errorw("cant create house game", "error", err.Error())
+			} else {
+				previous = gameId
+			}
+		}
+
+		timer := s.clock.NewTimer(entry.every())
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+	}
+}
+
+func (s *scheduler) waitFirstPlayer(gameId string) bool {
+	if gameId == "" {
+		return false
+	}
+
+	rec, err := s.store.GetGameById(s.ctx, gameId)
+	if err != nil {
+		return false
+	}
+
+	active := rec.GetState() == games.GameCreated || rec.GetState() == games.GameInProgress
+	return active && len(rec.GetPlayers()) == 0
+}
+
+func (s *scheduler) create(host games.Hosted, entry *Entry) (string, error) {
+	game, err := host.Host(s.runtime.Env(), s.house, entry.Settings)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.runtime.SubscribeOnGame(game, database.Hosted())
+	if err != nil {
+		return "", err
+	}
+
+	s.log.Infow("house game created", "game_id", game.GetID())
+
+	return game.GetID(), nil
+}
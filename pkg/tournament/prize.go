@@ -0,0 +1,63 @@
+package tournament
+
+import (
+	"math"
+
+	"github.com/PxyUp/ton_games_example/pkg/database"
+)
+
+// Distribute split prize pool of finished bracket in nano by player id.
+// Losers of the same round share places, e.g. semifinal losers split prizes of 3rd and 4th place equally,
+// rest after integer division goes to the champion.
+func Distribute(rec database.TournamentRecord) map[string]uint64 {
+	pool := uint64(rec.GetBuyIn()) * uint64(len(rec.GetPlayers()))
+
+	basisPoints := make([]uint64, len(rec.GetPrizes()))
+	for i, prize := range rec.GetPrizes() {
+		basisPoints[i] = uint64(math.Round(prize * 100))
+	}
+
+	prizeOfPlaces := func(place int, size int) uint64 {
+		sum := uint64(0)
+		for i := place - 1; i < place-1+size && i < len(basisPoints); i++ {
+			sum += basisPoints[i]
+		}
+
+		return pool * sum / 10000 / uint64(size)
+	}
+
+	roundSize := make(map[uint8]int)
+	for _, match := range rec.GetMatches() {
+		roundSize[match.GetRound()] += 1
+	}
+
+	result := make(map[string]uint64)
+	champion := ""
+	paid := uint64(0)
+	for _, match := range rec.GetMatches() {
+		winner := match.GetWinner()
+		if winner == "" {
+			continue
+		}
+
+		size := roundSize[match.GetRound()]
+		if size == 1 {
+			champion = winner
+		}
+
+		loser := match.GetPlayers()[0]
+		if loser == winner {
+			loser = match.GetPlayers()[1]
+		}
+
+		amount := prizeOfPlaces(size+1, size)
+		result[loser] = amount
+		paid += amount
+	}
+
+	if champion != "" {
+		result[champion] = pool - paid
+	}
+
+	return result
+}
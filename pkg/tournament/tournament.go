@@ -0,0 +1,375 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PxyUp/ton_games_example/games"
+	more_less "github.com/PxyUp/ton_games_example/games/more_less/game"
+	"github.com/PxyUp/ton_games_example/pkg/clock"
+	"github.com/PxyUp/ton_games_example/pkg/config"
+	"github.com/PxyUp/ton_games_example/pkg/database"
+	"github.com/PxyUp/ton_games_example/pkg/invite"
+	"github.com/PxyUp/ton_games_example/pkg/logger"
+	"github.com/PxyUp/ton_games_example/pkg/random"
+	"github.com/PxyUp/ton_games_example/pkg/runtime"
+)
+
+var (
+	_ Manager = &manager{}
+
+	ErrInvalidPlayers = fmt.Errorf("tournament players should be power of two from %d to %d", config.MIN_PLAYERS, config.MAX_TOURNAMENT_PLAYERS)
+	ErrInvalidPrizes  = errors.New("tournament prizes should be positive percents with sum 100, not more than players")
+)
+
+type TournamentApiConfig struct {
+	BuyIn                float64 `json:"buy_in"`
+	NumberOfPlayers      uint8   `json:"number_of_players"`
+	RegistrationDuration int     `json:"registration_duration"`
+	// Prizes percent of prize pool by place, first place by default take all
+	Prizes []float64 `json:"prizes"`
+}
+
+type Manager interface {
+	Create(ctx context.Context, creator string, cfg *TournamentApiConfig) (database.TournamentRecord, error)
+	// Register player in the tournament, full tournament started.
+	Register(ctx context.Context, tournamentId string, playerID string) (database.TournamentRecord, error)
+	Left(ctx context.Context, tournamentId string, playerID string) (database.TournamentRecord, error)
+}
+
+type manager struct {
+	ctx context.Context
+	// mutex serialize bracket changes
+	mutex sync.Mutex
+
+	store   database.DB
+	runtime runtime.Runtime
+	log     logger.Logger
+	clock   clock.Clock
+	random  random.Source
+}
+
+// New create tournament manager which play matches through the runtime, tournaments left from previous run resumed.
+func New(ctx context.Context, store database.DB, rt runtime.Runtime, logger2 logger.Logger) Manager {
+	m := &manager{
+		ctx:     ctx,
+		store:   store,
+		runtime: rt,
+		log:     logger2,
+		clock:   rt.Env().Clock,
+		random:  rt.Env().Random,
+	}
+
+	rt.OnResult(m.onResult)
+
+	m.resume()
+
+	return m
+}
+
+func validatePrizes(prizes []float64, players uint8) error {
+	if len(prizes) > int(players) {
+		return ErrInvalidPrizes
+	}
+
+	sum := 0.0
+	for _, prize := range prizes {
+		if prize <= 0 {
+			return ErrInvalidPrizes
+		}
+		sum += prize
+	}
+
+	if math.Abs(sum-100) > 1e-9 {
+		return ErrInvalidPrizes
+	}
+
+	return nil
+}
+
+func (m *manager) Create(ctx context.Context, creator string, cfg *TournamentApiConfig) (database.TournamentRecord, error) {
+	if cfg.BuyIn < config.MIN_GAME_COST || cfg.BuyIn > config.MAX_GAME_COST {
+		return nil, fmt.Errorf("tournament buy-in value from %.2f to %.2f", float64(config.MIN_GAME_COST), float64(config.MAX_GAME_COST))
+	}
+
+	if cfg.NumberOfPlayers < config.MIN_PLAYERS || cfg.NumberOfPlayers > config.MAX_TOURNAMENT_PLAYERS || cfg.NumberOfPlayers&(cfg.NumberOfPlayers-1) != 0 {
+		return nil, ErrInvalidPlayers
+	}
+
+	registrationDuration := time.Duration(cfg.RegistrationDuration) * time.Second
+	if registrationDuration < config.MIN_REGISTRATION_DURATION || registrationDuration > config.MAX_REGISTRATION_DURATION {
+		return nil, fmt.Errorf("tournament registration duration value from %s to %s", config.MIN_REGISTRATION_DURATION.String(), config.MAX_REGISTRATION_DURATION.String())
+	}
+
+	prizes := cfg.Prizes
+	if len(prizes) == 0 {
+		prizes = []float64{100}
+	}
+
+	errPrizes := validatePrizes(prizes, cfg.NumberOfPlayers)
+	if errPrizes != nil {
+		return nil, errPrizes
+	}
+
+	rec, err := m.store.CreateTournament(ctx, creator, &database.TournamentSettings{
+		BuyIn:                cfg.BuyIn,
+		MaxPlayers:           cfg.NumberOfPlayers,
+		RegistrationDeadline: m.clock.Now().Add(registrationDuration),
+		Prizes:               prizes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.expireAt(rec.GetId(), rec.GetRegistrationDeadline())
+
+	return rec, nil
+}
+
+func (m *manager) Register(ctx context.Context, tournamentId string, playerID string) (database.TournamentRecord, error) {
+	rec, err := m.store.RegisterInTournament(ctx, tournamentId, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rec.GetPlayers()) < int(rec.GetMaxPlayers()) {
+		return rec, nil
+	}
+
+	return m.start(rec)
+}
+
+func (m *manager) Left(ctx context.Context, tournamentId string, playerID string) (database.TournamentRecord, error) {
+	return m.store.LeftTournament(ctx, tournamentId, playerID)
+}
+
+// expireAt cancel tournament which not collected players before deadline.
+func (m *manager) expireAt(tournamentId string, deadline time.Time) {
+	timer := m.clock.NewTimer(deadline.Sub(m.clock.Now()))
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C():
+		}
+
+		_, err := m.store.CancelTournament(m.ctx, tournamentId)
+		if err != nil && !errors.Is(err, database.ErrTournamentClosed) {
+			m.log.Errorw("cant cancel expired tournament", "tournament_id", tournamentId, "error", err.Error())
+		}
+	}()
+}
+
+// start shuffle players into first round of the bracket.
+func (m *manager) start(rec database.TournamentRecord) (database.TournamentRecord, error) {
+	players := append([]string{}, rec.GetPlayers()...)
+	for i := len(players) - 1; i > 0; i-- {
+		j := random.Uint64n(m.random, uint64(i+1))
+		players[i], players[j] = players[j], players[i]
+	}
+
+	m.mutex.Lock()
+	started, err := m.store.StartTournamentRound(m.ctx, rec.GetId(), 1, pairsOf(players))
+	m.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.log.Infow("tournament started", "tournament_id", started.GetId())
+
+	for _, match := range roundMatches(started, 1) {
+		m.play(match)
+	}
+
+	return started, nil
+}
+
+func pairsOf(players []string) [][2]string {
+	pairs := make([][2]string, len(players)/2)
+	for i := range pairs {
+		pairs[i] = [2]string{players[2*i], players[2*i+1]}
+	}
+
+	return pairs
+}
+
+func roundMatches(rec database.TournamentRecord, round uint8) []database.TournamentMatchRecord {
+	var matches []database.TournamentMatchRecord
+	for _, match := range rec.GetMatches() {
+		if match.GetRound() == round {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// play create new game for the match, first player of the match is creator and second one joined by the system.
+func (m *manager) play(match database.TournamentMatchRecord) {
+	err := m.playMatch(match)
+	if err == nil {
+		return
+	}
+
+	m.log.Errorw("cant play tournament match, retry later", "match_id", strconv.FormatInt(match.GetId(), 10), "error", err.Error())
+
+	timer := m.clock.NewTimer(config.TOURNAMENT_MATCH_RETRY)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-timer.C():
+			m.play(match)
+		}
+	}()
+}
+
+func (m *manager) playMatch(match database.TournamentMatchRecord) error {
+	players := match.GetPlayers()
+
+	game, err := more_less.New(&games.MoreLessConfig{
+		NumberOfPlayers: 2,
+		Duration:        config.TOURNAMENT_MATCH_DURATION,
+		WaitAll:         true,
+		MaxRandom:       config.MAX_RANDOM,
+	}, players[0], nil, m.random, m.clock)
+	if err != nil {
+		return err
+	}
+
+	inviteCode, err := invite.NewCode(m.random)
+	if err != nil {
+		return err
+	}
+
+	// match is private, second player joined by the server
+	err = m.runtime.SubscribeOnGame(game, database.Private(inviteCode))
+	if err != nil {
+		return err
+	}
+
+	err = m.store.SetTournamentMatchGame(m.ctx, match.GetId(), game.GetID())
+	if err != nil {
+		_ = game.Abort()
+		return err
+	}
+
+	_, err = m.runtime.JoinGame(m.ctx, game, players[1])
+	if err != nil {
+		// game already bound to the match, result hook of aborted game play match again
+		m.log.Errorw("cant join tournament match", "match_id", strconv.FormatInt(match.GetId(), 10), "error", err.Error())
+		_ = game.Abort()
+		return nil
+	}
+
+	return nil
+}
+
+// onResult advance bracket by result of the match game, match without single winner (draw, error, abort) played again.
+func (m *manager) onResult(game games.Game, winners []games.Player) {
+	// games aborted on shutdown played again by resume
+	if m.ctx.Err() != nil {
+		return
+	}
+
+	rec, match, err := m.store.GetTournamentByGame(m.ctx, game.GetID())
+	if errors.Is(err, database.ErrTournamentMatchNotFound) {
+		return
+	}
+	if err != nil {
+		m.log.Errorw("cant get tournament of the game", "game_id", game.GetID(), "error", err.Error())
+		return
+	}
+
+	if len(winners) != 1 {
+		m.play(match)
+		return
+	}
+
+	m.mutex.Lock()
+	next, err := m.advance(rec, match, winners[0].GetId())
+	m.mutex.Unlock()
+	if err != nil {
+		m.log.Errorw("cant advance tournament", "match_id", strconv.FormatInt(match.GetId(), 10), "error", err.Error())
+		return
+	}
+
+	for _, nextMatch := range next {
+		m.play(nextMatch)
+	}
+}
+
+// advance store winner of the match, return matches of next round when round completed, should be called under mutex.
+func (m *manager) advance(rec database.TournamentRecord, match database.TournamentMatchRecord, winner string) ([]database.TournamentMatchRecord, error) {
+	current := match.GetRound()
+	matches := roundMatches(rec, current)
+
+	// losers of the round share places after winners of the round
+	rec, err := m.store.FinishTournamentMatch(m.ctx, match.GetId(), winner, uint8(len(matches)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	matches = roundMatches(rec, current)
+	roundWinners := make([]string, len(matches))
+	for i, roundMatch := range matches {
+		if roundMatch.GetWinner() == "" {
+			return nil, nil
+		}
+		roundWinners[i] = roundMatch.GetWinner()
+	}
+
+	if len(roundWinners) == 1 {
+		finished, errFinish := m.store.FinishTournament(m.ctx, rec.GetId(), Distribute(rec))
+		if errFinish != nil {
+			return nil, errFinish
+		}
+
+		m.log.Infow("tournament finished", "tournament_id", finished.GetId(), "winner", winner)
+		return nil, nil
+	}
+
+	rec, err = m.store.StartTournamentRound(m.ctx, rec.GetId(), current+1, pairsOf(roundWinners))
+	if err != nil {
+		return nil, err
+	}
+
+	return roundMatches(rec, current+1), nil
+}
+
+// resume continue tournaments after restart, matches without active game played again.
+func (m *manager) resume() {
+	list, err := m.store.GetTournaments(m.ctx, database.TournamentRegistration, database.TournamentRunning)
+	if err != nil {
+		m.log.Errorw("cant get tournaments", "error", err.Error())
+		return
+	}
+
+	for _, rec := range list {
+		if rec.GetState() == database.TournamentRegistration {
+			m.expireAt(rec.GetId(), rec.GetRegistrationDeadline())
+			continue
+		}
+
+		for _, match := range roundMatches(rec, rec.GetRound()) {
+			if match.GetWinner() != "" {
+				continue
+			}
+
+			if match.GetGameId() != "" {
+				if _, errGame := m.runtime.GetGame(m.ctx, match.GetGameId()); errGame == nil {
+					continue
+				}
+			}
+
+			m.play(match)
+		}
+	}
+}